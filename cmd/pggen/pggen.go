@@ -1,13 +1,21 @@
 package main
 
 import (
+	"compress/gzip"
 	"context"
 	"flag"
 	"fmt"
 	"github.com/bmatcuk/doublestar"
+	"github.com/jackc/pgx/v4"
 	"github.com/jschaf/pggen"
+	"github.com/jschaf/pggen/internal/ast"
 	"github.com/jschaf/pggen/internal/flags"
+	"github.com/jschaf/pggen/internal/migrate"
+	"github.com/jschaf/pggen/internal/sqlsplit"
+	"github.com/jschaf/pggen/internal/vet"
 	"github.com/peterbourgon/ff/v3/ffcli"
+	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"sort"
@@ -22,17 +30,32 @@ EXAMPLES
   # Generate code for a single query file using an existing postgres database.
   pggen gen go --query-glob author/queries.sql --postgres-connection "user=postgres port=5555 dbname=pggen"
 
-  # Generate code using Docker to create the postgres database with a schema 
-  # file. --schema-glob arg implies using Dockerized postgres.
+  # Generate code against an existing database the user can only SELECT from,
+  # like a managed read replica. Omitting --schema-glob makes pggen introspect
+  # information_schema/pg_catalog for schema info instead of applying DDL.
+  pggen gen go --query-glob author/queries.sql --postgres-connection "user=readonly host=replica dbname=pggen"
+
+  # Generate code using Docker to create the postgres database with a schema
+  # file. --schema-glob without --postgres-connection implies using
+  # Dockerized postgres.
   pggen gen go --schema-glob author/schema.sql --query-glob author/queries.sql
 
   # Generate code for all queries underneath a directory. Glob should be quoted
   # to prevent shell expansion.
   pggen gen go --schema-glob author/schema.sql --query-glob 'author/**/*.sql'
+
+  # Apply a schema file to an already-running postgres database instead of
+  # a Docker-provisioned one, e.g. a test database spun up by another tool.
+  pggen gen go --schema-glob author/schema.sql --query-glob author/queries.sql \
+    --postgres-connection "user=postgres port=5555 dbname=pggen"
+
+  # Lint query files against an existing database without generating code.
+  pggen vet --query-glob 'author/**/*.sql' --postgres-connection "user=postgres dbname=pggen"
 `
 
 func run() error {
 	genCmd := newGenCmd()
+	vetCmd := newVetCmd()
 	rootFlagSet := flag.NewFlagSet("root", flag.ExitOnError)
 	rootCmd := &ffcli.Command{
 		ShortUsage: "pggen <subcommand> [options...]",
@@ -40,6 +63,7 @@ func run() error {
 		FlagSet:    rootFlagSet,
 		Subcommands: []*ffcli.Command{
 			genCmd,
+			vetCmd,
 		},
 	}
 	rootCmd.Exec = func(ctx context.Context, args []string) error {
@@ -63,8 +87,15 @@ func newGenCmd() *ffcli.Command {
 	queryGlobs := flags.Strings(fset, "query-glob", nil,
 		"generate code for all SQL files that match glob, like 'queries/**/*.sql'")
 	schemaGlobs := flags.Strings(fset, "schema-glob", nil,
-		"create schema in Dockerized Postgres from all sql, sql.gz, or shell "+
-			"scripts (*.sh) that match a glob, like 'migrations/*.sql'")
+		"create schema from all sql, sql.gz, or shell scripts (*.sh) that "+
+			"match a glob, like 'migrations/*.sql'; runs in Dockerized "+
+			"Postgres unless --postgres-connection is also given, in which "+
+			"case the schema is applied directly to that database")
+	schemaSource := fset.String("schema-source", "glob",
+		"how to interpret --schema-glob: 'glob' treats each match as a schema "+
+			"file to run directly; 'migrations' treats each match as a "+
+			"golang-migrate-style migrations directory, applying only the "+
+			"*.up.sql (or *.up.sql.gz) files in numeric version order")
 	goSubCmd := &ffcli.Command{
 		Name:       "go",
 		ShortUsage: "pggen gen go [options...]",
@@ -75,19 +106,52 @@ func newGenCmd() *ffcli.Command {
 			if len(*queryGlobs) == 0 {
 				return fmt.Errorf("pggen gen go: at least one file in --query-glob must match")
 			}
-			if *schemaGlobs != nil && *postgresConn != "" {
-				return fmt.Errorf("cannot use both --schema-glob and --postgres-connection together\n" +
-					"    use --schema-glob to run dockerized postgres automatically\n" +
-					"    use --postgres-connection to connect to an existing database")
+			switch *schemaSource {
+			case "glob", "migrations":
+			default:
+				return fmt.Errorf("--schema-source must be 'glob' or 'migrations', got %q", *schemaSource)
 			}
 			queries, err := expandSortGlobs(*queryGlobs)
 			if err != nil {
 				return err
 			}
-			schemas, err := expandSortGlobs(*schemaGlobs)
+			var schemas []string
+			if *schemaSource == "migrations" {
+				schemas, err = expandMigrationDirs(*schemaGlobs)
+			} else {
+				schemas, err = expandSortGlobs(*schemaGlobs)
+			}
 			if err != nil {
 				return err
 			}
+			if err := validateSchemaFiles(schemas); err != nil {
+				return err
+			}
+			// dockerInitScripts is what we ask pggen.Generate to apply via its
+			// own Docker-provisioned Postgres. When --postgres-connection is
+			// also given, there's no Docker instance for Generate to manage,
+			// so we apply the schema files ourselves against that connection
+			// instead, via applySchemaFiles's sqlsplit.Run, and leave Generate
+			// nothing to do on that front.
+			//
+			// On the Docker-provisioned path, Generate applies dockerInitScripts
+			// itself, against a Postgres it starts and owns, without going
+			// through sqlsplit.Run. Split and statement-size errors are still
+			// caught for both paths by validateSchemaFiles above, but a Postgres
+			// execution error on the Docker path isn't reported with the failing
+			// statement's file:line the way applySchemaFiles reports it.
+			dockerInitScripts := schemas
+			switch {
+			case len(schemas) > 0 && *postgresConn != "":
+				if err := applySchemaFiles(ctx, *postgresConn, schemas); err != nil {
+					return err
+				}
+				dockerInitScripts = nil
+			case len(schemas) == 0 && *postgresConn != "":
+				if err := checkIntrospectableSchema(ctx, *postgresConn); err != nil {
+					return err
+				}
+			}
 			// Deduce output directory.
 			outDir := *outputDir
 			if outDir == "" {
@@ -104,7 +168,7 @@ func newGenCmd() *ffcli.Command {
 			err = pggen.Generate(pggen.GenerateOptions{
 				Language:          pggen.LangGo,
 				ConnString:        *postgresConn,
-				DockerInitScripts: schemas,
+				DockerInitScripts: dockerInitScripts,
 				QueryFiles:        queries,
 				OutputDir:         outDir,
 			})
@@ -127,6 +191,110 @@ func newGenCmd() *ffcli.Command {
 	return cmd
 }
 
+func newVetCmd() *ffcli.Command {
+	fset := flag.NewFlagSet("vet", flag.ExitOnError)
+	postgresConn := fset.String("postgres-connection", "",
+		`connection string to a postgres database to vet queries against, like: `+
+			`"user=postgres host=localhost dbname=pggen"`)
+	queryGlobs := flags.Strings(fset, "query-glob", nil,
+		"check all SQL files that match glob, like 'queries/**/*.sql'")
+	enableRules := flags.Strings(fset, "rule", nil,
+		"only run these rules instead of all default rules; may be repeated")
+	disableRules := flags.Strings(fset, "disable-rule", nil,
+		"skip these rules from the default set; may be repeated")
+	ruleConfigPath := fset.String("rule-config", "",
+		"path to a JSON file overriding --rule/--disable-rule for query files "+
+			"matching a glob; see vet.RuleConfig. Files that no entry matches "+
+			"keep running --rule/--disable-rule's rule set")
+	cmd := &ffcli.Command{
+		Name:       "vet",
+		ShortUsage: "pggen vet [options...]",
+		ShortHelp:  "lints Postgres query files without generating code",
+		FlagSet:    fset,
+		Exec: func(ctx context.Context, args []string) error {
+			if len(*queryGlobs) == 0 {
+				return fmt.Errorf("pggen vet: at least one file in --query-glob must match")
+			}
+			if *postgresConn == "" {
+				return fmt.Errorf("pggen vet: --postgres-connection is required")
+			}
+			fallbackRules, err := vet.SelectRules(*enableRules, *disableRules)
+			if err != nil {
+				return err
+			}
+			var ruleConfig vet.RuleConfig
+			if *ruleConfigPath != "" {
+				ruleConfig, err = vet.LoadRuleConfig(*ruleConfigPath)
+				if err != nil {
+					return err
+				}
+			}
+			queryFiles, err := expandSortGlobs(*queryGlobs)
+			if err != nil {
+				return err
+			}
+			parsedFiles, err := parseQueryFiles(queryFiles)
+			if err != nil {
+				return err
+			}
+
+			conn, err := pgx.Connect(ctx, *postgresConn)
+			if err != nil {
+				return fmt.Errorf("connect to postgres: %w", err)
+			}
+			defer conn.Close(ctx)
+
+			var violations []vet.Violation
+			queryCount := 0
+			for _, pf := range parsedFiles {
+				rules := fallbackRules
+				if ruleConfig != nil {
+					rules, err = ruleConfig.RulesFor(pf.Path, fallbackRules)
+					if err != nil {
+						return err
+					}
+				}
+				violations = append(violations, vet.NewRunner(rules).Check(ctx, conn, pf.Queries)...)
+				queryCount += len(pf.Queries)
+			}
+			for _, v := range violations {
+				fmt.Println(v.String())
+			}
+			if len(violations) > 0 {
+				return fmt.Errorf("pggen vet: found %d violation(s)", len(violations))
+			}
+			fmt.Printf("pggen vet: checked %d quer(ies) against %d default rule(s), no violations\n", queryCount, len(fallbackRules))
+			return nil
+		},
+	}
+	return cmd
+}
+
+// queryFile pairs a query file's parsed statements with the path they came
+// from, so newVetCmd's Exec can resolve a --rule-config glob against the
+// file a query actually lives in.
+type queryFile struct {
+	Path    string
+	Queries []*ast.SourceQuery
+}
+
+// parseQueryFiles parses every query file into its SourceQuery statements.
+func parseQueryFiles(files []string) ([]queryFile, error) {
+	parsed := make([]queryFile, 0, len(files))
+	for _, file := range files {
+		contents, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read query file %s: %w", file, err)
+		}
+		qs, err := ast.Parse(string(contents))
+		if err != nil {
+			return nil, fmt.Errorf("parse query file %s: %w", file, err)
+		}
+		parsed = append(parsed, queryFile{Path: file, Queries: qs})
+	}
+	return parsed, nil
+}
+
 // expandSortGlobs gets the absolute paths for all files matching globs. Order
 // files lexicographically within each glob but not across all globs. The order
 // of a glob relative to other globs is important for schemas where a schema
@@ -162,6 +330,160 @@ func expandSortGlobs(globs []string) ([]string, error) {
 	return files, nil
 }
 
+// expandMigrationDirs treats each glob match as a golang-migrate-style
+// migrations directory and returns the up-migration SQL files across all
+// directories, each ordered by numeric version within its directory. Unlike
+// expandSortGlobs, files are never sorted lexicographically: migration
+// version order takes precedence since "10_foo.up.sql" must run after
+// "2_bar.up.sql".
+func expandMigrationDirs(dirGlobs []string) ([]string, error) {
+	dirs, err := expandSortGlobs(dirGlobs)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(dirs)*8)
+	for _, dir := range dirs {
+		migrations, err := migrate.LoadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range migrations {
+			files = append(files, m.Path)
+		}
+	}
+	return files, nil
+}
+
+// checkIntrospectableSchema connects to connString and counts the tables and
+// views pginfer will have to resolve query types against when no
+// --schema-glob is given, failing fast with a clear error if introspection
+// finds nothing rather than letting codegen proceed against an empty
+// schema. It only counts rather than calling pg.FetchCatalog, since the
+// Inferrer fetches and caches the full catalog itself the first time it
+// needs one; fetching it here too would just pay for every table's columns
+// twice.
+func checkIntrospectableSchema(ctx context.Context, connString string) error {
+	conn, err := pgx.Connect(ctx, connString)
+	if err != nil {
+		return fmt.Errorf("connect to postgres for introspection: %w", err)
+	}
+	defer conn.Close(ctx)
+
+	count, err := countIntrospectableTables(ctx, conn)
+	if err != nil {
+		return fmt.Errorf("introspect schema: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("gen go: no --schema-glob given, but introspecting %q found no tables or views; "+
+			"pass --schema-glob or point --postgres-connection at a database that already has the schema",
+			connString)
+	}
+	fmt.Printf("gen go: no --schema-glob given, introspected %d table(s)/view(s) from the database\n", count)
+	return nil
+}
+
+// countIntrospectableTables counts the tables and views visible to conn,
+// using the same information_schema.tables filter as pg.FetchCatalog, but
+// without fetching any column metadata - checkIntrospectableSchema only
+// needs to know the schema isn't empty.
+func countIntrospectableTables(ctx context.Context, conn *pgx.Conn) (int, error) {
+	const query = `
+		SELECT count(*)
+		FROM information_schema.tables
+		WHERE table_schema != 'pg_catalog'
+		  AND table_schema != 'information_schema'`
+	var count int
+	if err := conn.QueryRow(ctx, query).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// applySchemaFiles runs each schema file's statements against connString
+// one at a time via sqlsplit.Run, so a real Postgres error during schema
+// setup reports the exact file:line that failed instead of one opaque
+// error for the whole file. This is how --schema-glob is applied when
+// --postgres-connection points at an already-running database instead of
+// one pggen.Generate would provision itself in Docker.
+func applySchemaFiles(ctx context.Context, connString string, files []string) error {
+	conn, err := pgx.Connect(ctx, connString)
+	if err != nil {
+		return fmt.Errorf("connect to postgres to apply schema: %w", err)
+	}
+	defer conn.Close(ctx)
+
+	for _, file := range files {
+		if strings.HasSuffix(file, ".sh") {
+			return fmt.Errorf("cannot apply shell script schema file %s to an existing "+
+				"--postgres-connection; shell scripts are only supported with "+
+				"Docker-provisioned --schema-glob", file)
+		}
+		contents, err := readSchemaFile(file)
+		if err != nil {
+			return err
+		}
+		if err := sqlsplit.Run(ctx, conn, file, contents, sqlsplit.DefaultMaxStatementSize); err != nil {
+			return fmt.Errorf("apply schema file: %w", err)
+		}
+	}
+	return nil
+}
+
+// validateSchemaFiles splits each schema file into individual statements and
+// checks that every one of them parses, so a malformed statement (an
+// unterminated quote or dollar-quote) surfaces as a file:line error before
+// the files are ever handed to Docker Postgres, instead of an opaque
+// failure from running the whole file at once.
+//
+// This only catches parse errors; it doesn't execute anything. Execution
+// happens once pggen.Generate brings up the Docker Postgres instance and
+// runs the schema files against it, or via applySchemaFiles when
+// --postgres-connection is given instead.
+func validateSchemaFiles(files []string) error {
+	for _, file := range files {
+		if strings.HasSuffix(file, ".sh") {
+			continue // shell scripts aren't SQL; run as-is.
+		}
+		contents, err := readSchemaFile(file)
+		if err != nil {
+			return err
+		}
+		if _, err := sqlsplit.Split(file, contents, sqlsplit.DefaultMaxStatementSize); err != nil {
+			return fmt.Errorf("invalid schema file: %w", err)
+		}
+	}
+	return nil
+}
+
+// readSchemaFile reads a schema file, transparently gunzipping it if it has
+// a .gz extension. This is the one place gzip decompression happens for a
+// schema file regardless of where it came from - a plain --schema-glob
+// match or a migration file's Path from expandMigrationDirs - so a
+// migrations-sourced Migration never needs its own reader.
+func readSchemaFile(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", fmt.Errorf("open schema file %s: %w", file, err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(file, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return "", fmt.Errorf("open gzipped schema file %s: %w", file, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	contents, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("read schema file %s: %w", file, err)
+	}
+	return string(contents), nil
+}
+
 func main() {
 	if err := run(); err != nil {
 		fmt.Printf("ERROR: %s\n", err.Error())
@@ -0,0 +1,63 @@
+package migrate
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pggen-migrate-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	write(t, dir, "0002_add_index.up.sql", "CREATE INDEX idx ON author (first_name);")
+	write(t, dir, "0002_add_index.down.sql", "DROP INDEX idx;")
+	write(t, dir, "0010_add_bio.up.sql", "ALTER TABLE author ADD COLUMN bio text;")
+	write(t, dir, "0001_create_author.up.sql", "CREATE TABLE author (author_id serial primary key);")
+	write(t, dir, "not_a_migration.sql", "SELECT 1;")
+	writeGzip(t, dir, "0003_add_bio.up.sql.gz", "ALTER TABLE author ADD COLUMN bio text;")
+
+	migrations, err := LoadDir(dir)
+	assert.NoError(t, err)
+
+	versions := make([]int, len(migrations))
+	for i, m := range migrations {
+		versions[i] = m.Version
+	}
+	assert.Equal(t, []int{1, 2, 3, 10}, versions, "migrations should be ordered numerically, not lexicographically")
+
+	var gzipped *Migration
+	for i, m := range migrations {
+		if m.Version == 3 {
+			gzipped = &migrations[i]
+		}
+	}
+	if gzipped == nil {
+		t.Fatal("expected to find the 0003 migration")
+	}
+	assert.True(t, gzipped.Gzipped, "0003_add_bio.up.sql.gz should be detected as gzipped")
+}
+
+// writeGzip writes a gzip-compressed file named name under dir, containing
+// contents.
+func writeGzip(t *testing.T, dir, name, contents string) {
+	t.Helper()
+	f, err := os.Create(filepath.Join(dir, name))
+	assert.NoError(t, err)
+	gz := gzip.NewWriter(f)
+	_, err = gz.Write([]byte(contents))
+	assert.NoError(t, err)
+	assert.NoError(t, gz.Close())
+	assert.NoError(t, f.Close())
+}
+
+func write(t *testing.T, dir, name, contents string) {
+	t.Helper()
+	err := ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,332 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// Catalog is a snapshot of a Postgres database's schema, built by
+// introspecting information_schema and pg_catalog rather than by reading
+// DDL. It lets pggen infer query types against a database it can only
+// SELECT from, such as a managed read replica, without ever creating
+// objects in it.
+type Catalog struct {
+	Tables     []CatalogTable
+	Enums      []CatalogEnum
+	Composites []CatalogComposite
+	Domains    []CatalogDomain
+}
+
+// CatalogTable is a single table or view discovered during introspection,
+// along with enough per-column metadata to determine output nullability
+// without running the query.
+type CatalogTable struct {
+	Schema  string
+	Name    string
+	Columns []CatalogColumn
+}
+
+// CatalogColumn describes one column of a CatalogTable. Nullable reflects
+// pg_attribute.attnotnull, together with a domain type's NOT NULL (see
+// fetchColumns), not the inferred nullability of any particular query
+// result; callers combine this with join structure to get a result
+// column's real nullability. IsPrimaryKey and IsUnique identify key columns
+// for callers that need one (e.g. to tell whether a query can return at
+// most one row). IsUnique doesn't affect Nullable - a UNIQUE column without
+// a NOT NULL constraint still permits NULL in Postgres - while a primary key
+// column is always reported with Nullable=false.
+type CatalogColumn struct {
+	Name         string
+	Type         Type
+	Nullable     bool
+	IsPrimaryKey bool
+	IsUnique     bool
+}
+
+// CatalogEnum is a CREATE TYPE ... AS ENUM discovered during introspection,
+// with its labels in the order Postgres sorts them (enumsortorder), which
+// is also the order comparisons between values use.
+type CatalogEnum struct {
+	Schema string
+	Name   string
+	Labels []string
+}
+
+// CatalogComposite is a CREATE TYPE ... AS (...) discovered during
+// introspection. Composite types are backed by a pg_class entry the same
+// way a table is, so its fields are fetched with the same query as a
+// table's columns.
+type CatalogComposite struct {
+	Schema  string
+	Name    string
+	Columns []CatalogColumn
+}
+
+// CatalogDomain is a CREATE DOMAIN discovered during introspection: a named
+// constraint (optionally NOT NULL) over an existing base type.
+type CatalogDomain struct {
+	Schema     string
+	Name       string
+	Underlying Type
+	NotNull    bool
+
+	oid uint32 // pg_type.oid; used by fetchColumns to resolve a domain-typed column to Underlying.
+}
+
+// FetchCatalog introspects every table, view, enum, composite type, and
+// domain visible in schemas (or all non-system schemas if schemas is empty)
+// and returns their columns, types, and not-null/primary-key metadata. This
+// is the read-only counterpart to running a schema.sql file through Docker
+// Postgres: it builds the same shape of schema information, but from an
+// existing database's catalog.
+//
+// Domains are resolved into table/composite columns: a column whose type is
+// a domain gets the domain's Underlying type and folds in its NOT NULL
+// constraint (see fetchColumns). Enums and Composites are returned on
+// Catalog as-is; nothing in this tree consumes them yet (no enum/composite
+// aware static analysis or codegen exists in this chunk), so they're
+// introspection-only until a consumer needs them.
+func FetchCatalog(ctx context.Context, conn *pgx.Conn, schemas []string) (*Catalog, error) {
+	// Domains are fetched first so their underlying types can be substituted
+	// into table/composite columns below: a column whose type is a domain
+	// would otherwise resolve via TypeFromOID to the domain's own (usually
+	// opaque) OID instead of the base type pginfer and codegen actually know
+	// how to handle.
+	domains, err := fetchDomains(ctx, conn, schemas)
+	if err != nil {
+		return nil, fmt.Errorf("fetch catalog domains: %w", err)
+	}
+	domainsByOID := make(map[uint32]CatalogDomain, len(domains))
+	for _, d := range domains {
+		domainsByOID[d.oid] = d
+	}
+
+	tables, err := fetchTables(ctx, conn, schemas)
+	if err != nil {
+		return nil, fmt.Errorf("fetch catalog tables: %w", err)
+	}
+	for i := range tables {
+		cols, err := fetchColumns(ctx, conn, tables[i].Schema, tables[i].Name, domainsByOID)
+		if err != nil {
+			return nil, fmt.Errorf("fetch columns for %s.%s: %w", tables[i].Schema, tables[i].Name, err)
+		}
+		tables[i].Columns = cols
+	}
+
+	enums, err := fetchEnums(ctx, conn, schemas)
+	if err != nil {
+		return nil, fmt.Errorf("fetch catalog enums: %w", err)
+	}
+
+	composites, err := fetchComposites(ctx, conn, schemas)
+	if err != nil {
+		return nil, fmt.Errorf("fetch catalog composite types: %w", err)
+	}
+	for i := range composites {
+		cols, err := fetchColumns(ctx, conn, composites[i].Schema, composites[i].Name, domainsByOID)
+		if err != nil {
+			return nil, fmt.Errorf("fetch columns for composite type %s.%s: %w", composites[i].Schema, composites[i].Name, err)
+		}
+		composites[i].Columns = cols
+	}
+
+	return &Catalog{Tables: tables, Enums: enums, Composites: composites, Domains: domains}, nil
+}
+
+func fetchTables(ctx context.Context, conn *pgx.Conn, schemas []string) ([]CatalogTable, error) {
+	const query = `
+		SELECT table_schema, table_name
+		FROM information_schema.tables
+		WHERE table_schema != 'pg_catalog'
+		  AND table_schema != 'information_schema'
+		  AND (array_length($1::text[], 1) IS NULL OR table_schema = ANY ($1::text[]))
+		ORDER BY table_schema, table_name`
+	rows, err := conn.Query(ctx, query, schemas)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []CatalogTable
+	for rows.Next() {
+		t := CatalogTable{}
+		if err := rows.Scan(&t.Schema, &t.Name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, t)
+	}
+	return tables, rows.Err()
+}
+
+// fetchColumns returns a table's columns in ordinal position order, from
+// pg_attribute/pg_constraint rather than information_schema, which doesn't
+// expose attnotnull directly for all column kinds (e.g. columns inherited
+// via domains). Nullable is resolved from attnotnull alone; IsPrimaryKey and
+// IsUnique are resolved from pg_constraint and identify key columns but
+// don't independently affect Nullable, since a UNIQUE constraint without
+// NOT NULL still permits NULL. A primary key does imply NOT NULL in
+// Postgres, so IsPrimaryKey additionally hardens Nullable=false as a
+// defense against that invariant somehow not showing up in attnotnull.
+//
+// domains maps a domain type's pg_type.oid to its CatalogDomain, as
+// returned by fetchDomains. A column whose atttypid is a domain is resolved
+// to the domain's Underlying type, with the domain's own NOT NULL
+// constraint folded into Nullable alongside the column's own attnotnull.
+func fetchColumns(ctx context.Context, conn *pgx.Conn, schema, table string, domains map[uint32]CatalogDomain) ([]CatalogColumn, error) {
+	const query = `
+		SELECT
+			a.attname,
+			a.atttypid,
+			a.attnotnull,
+			COALESCE(pk.is_primary_key, false),
+			COALESCE(uniq.is_unique, false)
+		FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN (
+			SELECT unnest(conkey) AS attnum, conrelid
+			FROM pg_constraint
+			WHERE contype = 'p'
+		) pk_raw ON pk_raw.attnum = a.attnum AND pk_raw.conrelid = a.attrelid
+		LEFT JOIN LATERAL (SELECT true AS is_primary_key) pk ON pk_raw.attnum IS NOT NULL
+		LEFT JOIN (
+			SELECT unnest(conkey) AS attnum, conrelid
+			FROM pg_constraint
+			WHERE contype = 'u'
+		) uniq_raw ON uniq_raw.attnum = a.attnum AND uniq_raw.conrelid = a.attrelid
+		LEFT JOIN LATERAL (SELECT true AS is_unique) uniq ON uniq_raw.attnum IS NOT NULL
+		WHERE n.nspname = $1
+		  AND c.relname = $2
+		  AND a.attnum > 0
+		  AND NOT a.attisdropped
+		ORDER BY a.attnum`
+	rows, err := conn.Query(ctx, query, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cols []CatalogColumn
+	for rows.Next() {
+		var oid uint32
+		c := CatalogColumn{}
+		if err := rows.Scan(&c.Name, &oid, &c.Nullable, &c.IsPrimaryKey, &c.IsUnique); err != nil {
+			return nil, err
+		}
+		c.Nullable = !c.Nullable // attnotnull is "not null"; we store "nullable".
+		if d, ok := domains[oid]; ok {
+			c.Type = d.Underlying
+			if d.NotNull {
+				c.Nullable = false
+			}
+		} else {
+			c.Type = TypeFromOID(oid)
+		}
+		if c.IsPrimaryKey {
+			c.Nullable = false
+		}
+		cols = append(cols, c)
+	}
+	return cols, rows.Err()
+}
+
+// fetchEnums returns every CREATE TYPE ... AS ENUM visible in schemas (or
+// all non-system schemas if schemas is empty), with each enum's labels in
+// enumsortorder.
+func fetchEnums(ctx context.Context, conn *pgx.Conn, schemas []string) ([]CatalogEnum, error) {
+	const query = `
+		SELECT n.nspname, t.typname, e.enumlabel
+		FROM pg_type t
+		JOIN pg_enum e ON e.enumtypid = t.oid
+		JOIN pg_namespace n ON n.oid = t.typnamespace
+		WHERE n.nspname != 'pg_catalog'
+		  AND n.nspname != 'information_schema'
+		  AND (array_length($1::text[], 1) IS NULL OR n.nspname = ANY ($1::text[]))
+		ORDER BY n.nspname, t.typname, e.enumsortorder`
+	rows, err := conn.Query(ctx, query, schemas)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var enums []CatalogEnum
+	for rows.Next() {
+		var schema, name, label string
+		if err := rows.Scan(&schema, &name, &label); err != nil {
+			return nil, err
+		}
+		if n := len(enums); n > 0 && enums[n-1].Schema == schema && enums[n-1].Name == name {
+			enums[n-1].Labels = append(enums[n-1].Labels, label)
+			continue
+		}
+		enums = append(enums, CatalogEnum{Schema: schema, Name: name, Labels: []string{label}})
+	}
+	return enums, rows.Err()
+}
+
+// fetchComposites returns every CREATE TYPE ... AS (...) visible in schemas
+// (or all non-system schemas if schemas is empty), without their columns;
+// the caller fetches each composite's columns with fetchColumns, the same
+// way it does for a table, since a composite type is backed by a pg_class
+// entry just like a table is.
+func fetchComposites(ctx context.Context, conn *pgx.Conn, schemas []string) ([]CatalogComposite, error) {
+	const query = `
+		SELECT n.nspname, c.relname
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.relkind = 'c'
+		  AND n.nspname != 'pg_catalog'
+		  AND n.nspname != 'information_schema'
+		  AND (array_length($1::text[], 1) IS NULL OR n.nspname = ANY ($1::text[]))
+		ORDER BY n.nspname, c.relname`
+	rows, err := conn.Query(ctx, query, schemas)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var composites []CatalogComposite
+	for rows.Next() {
+		c := CatalogComposite{}
+		if err := rows.Scan(&c.Schema, &c.Name); err != nil {
+			return nil, err
+		}
+		composites = append(composites, c)
+	}
+	return composites, rows.Err()
+}
+
+// fetchDomains returns every CREATE DOMAIN visible in schemas (or all
+// non-system schemas if schemas is empty), resolving the domain's NOT NULL
+// constraint from pg_type.typnotnull and its underlying type from
+// pg_type.typbasetype, the same way fetchColumns resolves a column's.
+func fetchDomains(ctx context.Context, conn *pgx.Conn, schemas []string) ([]CatalogDomain, error) {
+	const query = `
+		SELECT t.oid, n.nspname, t.typname, t.typbasetype, t.typnotnull
+		FROM pg_type t
+		JOIN pg_namespace n ON n.oid = t.typnamespace
+		WHERE t.typtype = 'd'
+		  AND n.nspname != 'pg_catalog'
+		  AND n.nspname != 'information_schema'
+		  AND (array_length($1::text[], 1) IS NULL OR n.nspname = ANY ($1::text[]))
+		ORDER BY n.nspname, t.typname`
+	rows, err := conn.Query(ctx, query, schemas)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var domains []CatalogDomain
+	for rows.Next() {
+		var baseOID uint32
+		d := CatalogDomain{}
+		if err := rows.Scan(&d.oid, &d.Schema, &d.Name, &baseOID, &d.NotNull); err != nil {
+			return nil, err
+		}
+		d.Underlying = TypeFromOID(baseOID)
+		domains = append(domains, d)
+	}
+	return domains, rows.Err()
+}
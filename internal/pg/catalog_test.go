@@ -0,0 +1,99 @@
+package pg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jschaf/pggen/internal/pgtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchCatalog(t *testing.T) {
+	conn, cleanupFunc := pgtest.NewPostgresSchema(t, []string{
+		"../../example/author/schema.sql",
+	})
+	defer cleanupFunc()
+
+	catalog, err := FetchCatalog(context.Background(), conn, []string{"public"})
+	assert.NoError(t, err)
+
+	var author *CatalogTable
+	for i, tbl := range catalog.Tables {
+		if tbl.Name == "author" {
+			author = &catalog.Tables[i]
+		}
+	}
+	if author == nil {
+		t.Fatal("expected to find author table in catalog")
+	}
+
+	var firstName *CatalogColumn
+	for i, col := range author.Columns {
+		if col.Name == "first_name" {
+			firstName = &author.Columns[i]
+		}
+	}
+	if firstName == nil {
+		t.Fatal("expected to find first_name column")
+	}
+	assert.Equal(t, Text, firstName.Type)
+	assert.False(t, firstName.Nullable, "first_name is declared NOT NULL in schema.sql")
+	assert.False(t, firstName.IsUnique, "first_name has no UNIQUE constraint in schema.sql")
+
+	var authorID *CatalogColumn
+	for i, col := range author.Columns {
+		if col.Name == "author_id" {
+			authorID = &author.Columns[i]
+		}
+	}
+	if authorID == nil {
+		t.Fatal("expected to find author_id column")
+	}
+	assert.True(t, authorID.IsPrimaryKey)
+}
+
+// TestFetchCatalog_UniqueAndDomain covers the positive cases TestFetchCatalog
+// doesn't: a column that actually has a UNIQUE constraint, and a domain-typed
+// column resolving to its underlying type with the domain's NOT NULL folded
+// in.
+func TestFetchCatalog_UniqueAndDomain(t *testing.T) {
+	conn, cleanupFunc := pgtest.NewPostgresSchema(t, []string{
+		"testdata/unique_and_domain_schema.sql",
+	})
+	defer cleanupFunc()
+
+	catalog, err := FetchCatalog(context.Background(), conn, []string{"public"})
+	assert.NoError(t, err)
+
+	var publisher *CatalogTable
+	for i, tbl := range catalog.Tables {
+		if tbl.Name == "publisher" {
+			publisher = &catalog.Tables[i]
+		}
+	}
+	if publisher == nil {
+		t.Fatal("expected to find publisher table in catalog")
+	}
+
+	var name *CatalogColumn
+	var contact *CatalogColumn
+	for i, col := range publisher.Columns {
+		switch col.Name {
+		case "name":
+			name = &publisher.Columns[i]
+		case "contact":
+			contact = &publisher.Columns[i]
+		}
+	}
+	if name == nil {
+		t.Fatal("expected to find name column")
+	}
+	if contact == nil {
+		t.Fatal("expected to find contact column")
+	}
+
+	assert.True(t, name.IsUnique, "name has a UNIQUE constraint in unique_and_domain_schema.sql")
+
+	assert.Equal(t, Text, contact.Type, "contact is domain email, which is declared AS text")
+	assert.False(t, contact.Nullable, "email domain is declared NOT NULL")
+}
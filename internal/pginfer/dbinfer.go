@@ -0,0 +1,82 @@
+package pginfer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jschaf/pggen/internal/ast"
+	"github.com/jschaf/pggen/internal/pg"
+)
+
+// inferDB resolves query's types by asking Postgres directly: it PREPAREs
+// the query and reads the resulting parameter and result-row descriptions.
+// This is the fallback path used when the static analyzer in static.go
+// can't confidently resolve a query.
+func (i *Inferrer) inferDB(query *ast.SourceQuery) (TypedQuery, error) {
+	ctx := context.Background()
+	desc, err := i.conn.Prepare(ctx, "", query.PreparedSQL)
+	if err != nil {
+		return TypedQuery{}, fmt.Errorf("prepare query %s: %w", query.Name, err)
+	}
+	defer func() { _ = i.conn.Deallocate(ctx, "") }()
+
+	if err := checkResultKind(query, len(desc.Fields)); err != nil {
+		return TypedQuery{}, err
+	}
+
+	var inputs []InputParam
+	if len(query.ParamNames) > 0 {
+		inputs = make([]InputParam, len(query.ParamNames))
+	}
+	for idx, name := range query.ParamNames {
+		pgType := pg.Unknown
+		if idx < len(desc.ParamOIDs) {
+			pgType = pg.TypeFromOID(desc.ParamOIDs[idx])
+		}
+		inputs[idx] = InputParam{PgName: name, PgType: pgType}
+	}
+
+	var outputs []OutputColumn
+	if len(desc.Fields) > 0 {
+		fieldNames := make([]string, len(desc.Fields))
+		for idx, field := range desc.Fields {
+			fieldNames[idx] = string(field.Name)
+		}
+		nullable, err := i.resolveNullability(ctx, query.PreparedSQL, fieldNames)
+		if err != nil {
+			return TypedQuery{}, fmt.Errorf("resolve nullability for query %s: %w", query.Name, err)
+		}
+		outputs = make([]OutputColumn, len(desc.Fields))
+		for idx, field := range desc.Fields {
+			outputs[idx] = OutputColumn{
+				PgName:   fieldNames[idx],
+				PgType:   pg.TypeFromOID(uint32(field.DataTypeOID)),
+				Nullable: nullable[idx],
+			}
+		}
+	}
+
+	return TypedQuery{
+		Name:        query.Name,
+		ResultKind:  query.ResultKind,
+		Doc:         newDoc(query.Doc),
+		PreparedSQL: query.PreparedSQL,
+		Inputs:      inputs,
+		Outputs:     outputs,
+		Analyzer:    AnalyzerDB,
+	}, nil
+}
+
+// checkResultKind validates that a query's declared result kind (:one,
+// :many, :exec) is consistent with whether Postgres actually returns rows
+// for it.
+func checkResultKind(query *ast.SourceQuery, numFields int) error {
+	if numFields == 0 && query.ResultKind != ast.ResultKindExec {
+		return fmt.Errorf(
+			"query %s has incompatible result kind :%s; "+
+				"the query doesn't return any rows; "+
+				"use :exec if query shouldn't return rows",
+			query.Name, query.ResultKind)
+	}
+	return nil
+}
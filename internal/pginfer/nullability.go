@@ -0,0 +1,470 @@
+package pginfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// joinClauseRe splits a FROM clause on join boundaries, capturing the join
+// keyword (LEFT/RIGHT/FULL, or empty for a plain/INNER join) that precedes
+// each joined table.
+var joinClauseRe = regexp.MustCompile(`(?i)\b(LEFT|RIGHT|FULL)?\s*(?:OUTER\s+)?(?:INNER\s+)?JOIN\b`)
+
+// fromClauseEndAnchoredRe matches, at the very start of the string it's
+// applied to, whichever keyword ends a FROM clause: WHERE, GROUP BY,
+// HAVING, WINDOW, ORDER BY, a row-locking clause (FOR UPDATE/SHARE/NO KEY
+// UPDATE/KEY SHARE), RETURNING, or LIMIT/OFFSET. findClauseEnd anchors it at
+// each depth-0 candidate position instead of letting it scan the whole
+// clause, so a WHERE/GROUP BY/etc. inside a parenthesized derived table's
+// own subquery doesn't get mistaken for where the outer FROM list ends.
+var fromClauseEndAnchoredRe = regexp.MustCompile(`(?i)^(WHERE|GROUP BY|HAVING|WINDOW|ORDER BY|FOR (?:NO KEY )?UPDATE|FOR KEY SHARE|FOR SHARE|RETURNING|LIMIT|OFFSET)\b`)
+
+// onOrUsingRe trims the join condition ("ON ..." or "USING (...)") off a
+// join segment, leaving just the table reference.
+var onOrUsingRe = regexp.MustCompile(`(?i)\b(ON|USING)\b`)
+
+// colRefRe recognizes a bare column reference in a SELECT list, optionally
+// qualified by a table alias, e.g. "first_name" or "a1.first_name". Any
+// other shape - a CASE expression, a function call, a cast - is treated as
+// an expression that can produce NULL regardless of its inputs.
+var colRefRe = regexp.MustCompile(`^(?:([a-zA-Z_][a-zA-Z0-9_]*)\.)?([a-zA-Z_][a-zA-Z0-9_]*)$`)
+
+// tableRef is a single table reference in a query's FROM clause.
+type tableRef struct {
+	Alias      string // empty if the table isn't aliased
+	Name       string
+	Nullable   bool // true if this table is on the nullable side of an OUTER JOIN
+	IsSubquery bool // true for a parenthesized "(SELECT ...) alias" derived table
+}
+
+func (t tableRef) matches(qualifier string) bool {
+	if qualifier == "" {
+		return true
+	}
+	return qualifier == t.Alias || qualifier == t.Name
+}
+
+// parseFromClause extracts the tables referenced in sql's FROM clause, and
+// marks which ones sit on the nullable side of a LEFT/RIGHT/FULL OUTER
+// JOIN. An UPDATE statement has no FROM clause at all, so it falls back to
+// parseUpdateTarget to resolve the single table its RETURNING list reads
+// from. It returns nil for anything else with no FROM clause, such as a
+// literal SELECT.
+//
+// This is a lightweight, text-based pass rather than a true parse-tree
+// walk built on pg_query_go: it's good enough for the single- and
+// multi-join FROM clauses pggen queries typically use, at the cost of
+// needing findClauseEnd/findTopLevelKeyword to enumerate every clause
+// keyword that can follow a FROM list by hand (see fromClauseEndAnchoredRe)
+// instead of getting clause boundaries for free from a real grammar. Both
+// the static analyzer and inferDB's resolveNullability read nullability
+// from this pass, so a clause boundary it misses can yield a wrong
+// nullability result, not only a fallback to PREPARE/DESCRIBE.
+//
+// A parenthesized derived table in the FROM list is
+// recognized as a subquery (see parseTableRef) and conservatively treated
+// as nullable, since its output columns aren't resolvable against the
+// catalog without actually parsing the subquery's own SELECT list. The FROM
+// keyword itself is located with findTopLevelKeyword so a FROM inside a
+// function call, e.g. extract(epoch FROM ts), isn't mistaken for the
+// clause's FROM. A comma-separated table list, e.g. "FROM a, b", is parsed
+// as two plain (cross-joined) refs rather than one bogus table named "a,".
+func parseFromClause(sql string) []tableRef {
+	fromIdx := findTopLevelKeyword(sql, "FROM")
+	if fromIdx == -1 {
+		return parseUpdateTarget(sql)
+	}
+	clause := sql[fromIdx+len("FROM"):]
+	if end := findClauseEnd(clause); end != -1 {
+		clause = clause[:end]
+	} else {
+		clause = strings.TrimSuffix(strings.TrimSpace(clause), ";")
+	}
+
+	joinKinds := joinClauseRe.FindAllStringSubmatch(clause, -1)
+	segments := joinClauseRe.Split(clause, -1)
+
+	refs := parseTableRefList(segments[0])
+	if refs == nil {
+		return nil
+	}
+
+	for i, seg := range segments[1:] {
+		segRefs := parseTableRefList(seg)
+		if segRefs == nil {
+			continue
+		}
+		kind := strings.ToUpper(strings.TrimSpace(joinKinds[i][1]))
+		switch kind {
+		case "LEFT":
+			for j := range segRefs {
+				segRefs[j].Nullable = true
+			}
+		case "RIGHT":
+			for j := range refs {
+				refs[j].Nullable = true
+			}
+		case "FULL":
+			for j := range refs {
+				refs[j].Nullable = true
+			}
+			for j := range segRefs {
+				segRefs[j].Nullable = true
+			}
+		}
+		refs = append(refs, segRefs...)
+	}
+	return refs
+}
+
+// findClauseEnd returns the index in clause where the FROM list ends, i.e.
+// the earliest depth-0, outside-any-string occurrence of one of the
+// keywords fromClauseEndAnchoredRe matches, or -1 if none is found. It walks
+// clause the same way findTopLevelKeyword walks a whole statement, tracking
+// paren depth and single-quoted strings, so a WHERE/GROUP BY/etc. that
+// belongs to a parenthesized derived table's own subquery - e.g. "(SELECT
+// id FROM book WHERE ...) b" - isn't mistaken for the outer FROM clause's
+// end and doesn't truncate the join list that follows it.
+func findClauseEnd(clause string) int {
+	depth := 0
+	inString := false
+	for i := 0; i < len(clause); i++ {
+		switch {
+		case clause[i] == '\'':
+			inString = !inString
+		case inString:
+			continue
+		case clause[i] == '(':
+			depth++
+		case clause[i] == ')':
+			depth--
+		case depth == 0 && (i == 0 || !isIdentByte(clause[i-1])) && fromClauseEndAnchoredRe.MatchString(clause[i:]):
+			return i
+		}
+	}
+	return -1
+}
+
+// findTopLevelKeyword returns the index of the first occurrence of keyword
+// in sql as a whole word, outside of any single-quoted string and outside
+// any parenthesized group, or -1 if there is none. This is what lets
+// parseFromClause and parseSelectList skip a FROM or RETURNING that
+// appears inside a function call or subquery and find the statement's own
+// clause instead.
+func findTopLevelKeyword(sql, keyword string) int {
+	upper := strings.ToUpper(sql)
+	keyword = strings.ToUpper(keyword)
+	depth := 0
+	inString := false
+	for i := 0; i < len(sql); i++ {
+		switch {
+		case sql[i] == '\'':
+			inString = !inString
+		case inString:
+			continue
+		case sql[i] == '(':
+			depth++
+		case sql[i] == ')':
+			depth--
+		case depth == 0 && isWordAt(upper, i, keyword):
+			return i
+		}
+	}
+	return -1
+}
+
+// isWordAt reports whether word occurs at index i in s as a whole word,
+// i.e. not immediately preceded or followed by another identifier byte.
+func isWordAt(s string, i int, word string) bool {
+	if i+len(word) > len(s) || s[i:i+len(word)] != word {
+		return false
+	}
+	if i > 0 && isIdentByte(s[i-1]) {
+		return false
+	}
+	if end := i + len(word); end < len(s) && isIdentByte(s[end]) {
+		return false
+	}
+	return true
+}
+
+func isIdentByte(b byte) bool {
+	return b == '_' || ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z') || ('0' <= b && b <= '9')
+}
+
+// parseUpdateTarget resolves the single table an UPDATE statement writes
+// to, e.g. "author" from "UPDATE author SET first_name = $1 RETURNING
+// author_id". UPDATE has no FROM clause, so without this, a RETURNING
+// column has no table to resolve against and resolveNullability falls back
+// to conservatively marking even a declared-NOT-NULL column nullable.
+// Returns nil for any statement that isn't an UPDATE.
+func parseUpdateTarget(sql string) []tableRef {
+	upper := strings.ToUpper(strings.TrimSpace(sql))
+	if !strings.HasPrefix(upper, "UPDATE") {
+		return nil
+	}
+	rest := sql[strings.Index(upper, "UPDATE")+len("UPDATE"):]
+	setIdx := findTopLevelKeyword(rest, "SET")
+	if setIdx == -1 {
+		return nil
+	}
+	ref := parseTableRef(rest[:setIdx])
+	if ref == nil {
+		return nil
+	}
+	return []tableRef{*ref}
+}
+
+// parseTableRefList parses a single FROM/JOIN segment that may hold a
+// comma-separated list of table references, e.g. "author a1, book b1", into
+// one tableRef per table. Returns nil if segment has no table reference at
+// all.
+func parseTableRefList(segment string) []tableRef {
+	parts := splitTopLevelParen(segment, ',')
+	refs := make([]tableRef, 0, len(parts))
+	for _, part := range parts {
+		ref := parseTableRef(part)
+		if ref == nil {
+			continue
+		}
+		refs = append(refs, *ref)
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+	return refs
+}
+
+// parseTableRef parses a single FROM/JOIN segment like " author a1 " or
+// " author a1 ON a1.author_id = a2.author_id" into its table name and
+// alias, discarding the join condition. A parenthesized derived table, e.g.
+// " (SELECT ...) sub", is recognized as a subquery rather than mis-parsed
+// as a table literally named "(SELECT": its columns can't be resolved
+// against the catalog here, so callers treat it as conservatively
+// nullable instead of querying a nonexistent table.
+func parseTableRef(segment string) *tableRef {
+	if loc := onOrUsingRe.FindStringIndex(segment); loc != nil {
+		segment = segment[:loc[0]]
+	}
+	segment = strings.TrimSpace(strings.Trim(segment, ","))
+	if segment == "" {
+		return nil
+	}
+	if segment[0] == '(' {
+		rest, ok := skipParenGroup(segment)
+		if !ok {
+			return nil
+		}
+		rest = strings.TrimSpace(rest)
+		fields := strings.Fields(rest)
+		ref := &tableRef{IsSubquery: true}
+		switch {
+		case len(fields) == 0:
+			// no alias
+		case strings.EqualFold(fields[0], "AS") && len(fields) > 1:
+			ref.Alias = fields[1]
+		default:
+			ref.Alias = fields[0]
+		}
+		return ref
+	}
+	fields := strings.Fields(segment)
+	ref := &tableRef{Name: fields[0]}
+	switch len(fields) {
+	case 1:
+		// no alias
+	case 2:
+		ref.Alias = fields[1]
+	default:
+		if strings.EqualFold(fields[1], "AS") {
+			ref.Alias = fields[2]
+		} else {
+			ref.Alias = fields[1]
+		}
+	}
+	return ref
+}
+
+// skipParenGroup returns the text following the parenthesized group that
+// segment starts with, e.g. "(SELECT 1) sub" -> " sub". Returns ok=false if
+// the parens are unbalanced.
+func skipParenGroup(segment string) (string, bool) {
+	depth := 0
+	for i, c := range segment {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return segment[i+1:], true
+			}
+		}
+	}
+	return "", false
+}
+
+// selectItem classifies one item in a SELECT list for nullability
+// purposes.
+type selectItem struct {
+	Qualifier string // table alias/name the column is qualified with, if any
+	Column    string // column name, if this is a simple column reference
+	Alias     string // explicit "AS alias", if any
+	IsColumn  bool   // false for expressions like CASE, COALESCE, function calls
+}
+
+// parseSelectList classifies each item in sql's SELECT list, in order, so
+// callers can zip the result up positionally against PREPARE's result field
+// descriptions.
+func parseSelectList(sql string) []selectItem {
+	upper := strings.ToUpper(sql)
+	if !strings.HasPrefix(strings.TrimSpace(upper), "SELECT") {
+		// DELETE/UPDATE ... RETURNING: the projection is the RETURNING list.
+		idx := findTopLevelKeyword(sql, "RETURNING")
+		if idx == -1 {
+			return nil
+		}
+		return parseProjection(sql[idx+len("RETURNING"):])
+	}
+	rest := sql[strings.Index(upper, "SELECT")+len("SELECT"):]
+	if fromIdx := findTopLevelKeyword(rest, "FROM"); fromIdx != -1 {
+		rest = rest[:fromIdx]
+	}
+	return parseProjection(rest)
+}
+
+func parseProjection(projection string) []selectItem {
+	projection = strings.TrimSuffix(strings.TrimSpace(projection), ";")
+	items := splitTopLevelParen(projection, ',')
+	result := make([]selectItem, 0, len(items))
+	for _, item := range items {
+		expr, alias, ok := splitAlias(item)
+		if !ok {
+			expr = strings.TrimSpace(item)
+		}
+		if m := colRefRe.FindStringSubmatch(expr); m != nil {
+			result = append(result, selectItem{Qualifier: m[1], Column: m[2], Alias: alias, IsColumn: true})
+		} else {
+			result = append(result, selectItem{Alias: alias, IsColumn: false})
+		}
+	}
+	return result
+}
+
+// splitTopLevelParen splits s on sep, ignoring sep inside single-quoted
+// strings or parentheses, so "COALESCE(a, b)" isn't split on its internal
+// comma.
+func splitTopLevelParen(s string, sep byte) []string {
+	var parts []string
+	start := 0
+	depth := 0
+	inString := false
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\'':
+			inString = !inString
+		case '(':
+			if !inString {
+				depth++
+			}
+		case ')':
+			if !inString {
+				depth--
+			}
+		case sep:
+			if !inString && depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	parts = append(parts, s[start:])
+	return parts
+}
+
+// resolveNullability determines, for each output field of a prepared query
+// in order, whether it can be NULL. A column is nullable only when:
+//   - it's on the nullable side of a LEFT/RIGHT/FULL OUTER JOIN, or
+//   - it's not a plain column reference at all (CASE, COALESCE, a function
+//     call, or similar can always produce NULL), or
+//   - the underlying column itself allows NULL (pg_attribute.attnotnull is
+//     false), or
+//   - it can't be traced to a cataloged table column: it comes from a
+//     derived-table subquery, its qualifier or column isn't found, or there
+//     are more result fields than parsed SELECT-list items.
+//
+// A column from a table that isn't on the nullable side of any join, and
+// whose declared type doesn't allow NULL, is reported as not nullable -
+// unlike the naive "any join makes every column nullable" heuristic this
+// replaces, which falsely marked INNER JOIN columns nullable.
+func (i *Inferrer) resolveNullability(ctx context.Context, sql string, fieldNames []string) ([]bool, error) {
+	refs := parseFromClause(sql)
+	items := parseSelectList(sql)
+
+	nullable := make([]bool, len(fieldNames))
+	for idx := range fieldNames {
+		if idx >= len(items) {
+			nullable[idx] = true // couldn't classify this column; be conservative.
+			continue
+		}
+		item := items[idx]
+		if !item.IsColumn {
+			nullable[idx] = true
+			continue
+		}
+		ref := findTableRef(refs, item.Qualifier)
+		if ref == nil {
+			nullable[idx] = true
+			continue
+		}
+		if ref.Nullable || ref.IsSubquery {
+			nullable[idx] = true
+			continue
+		}
+		notNull, found, err := i.columnNotNull(ctx, ref.Name, item.Column)
+		if err != nil {
+			return nil, err
+		}
+		nullable[idx] = !(found && notNull)
+	}
+	return nullable, nil
+}
+
+func findTableRef(refs []tableRef, qualifier string) *tableRef {
+	if qualifier == "" && len(refs) == 1 {
+		return &refs[0]
+	}
+	for idx := range refs {
+		if refs[idx].matches(qualifier) {
+			return &refs[idx]
+		}
+	}
+	return nil
+}
+
+// columnNotNull reports whether table.column is declared NOT NULL,
+// according to pg_attribute.attnotnull.
+func (i *Inferrer) columnNotNull(ctx context.Context, table, column string) (notNull bool, found bool, err error) {
+	const query = `
+		SELECT a.attnotnull
+		FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid
+		WHERE c.relname = $1
+		  AND a.attname = $2
+		  AND a.attnum > 0
+		  AND NOT a.attisdropped
+		LIMIT 1`
+	row := i.conn.QueryRow(ctx, query, table, column)
+	if err := row.Scan(&notNull); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, false, nil
+		}
+		return false, false, fmt.Errorf("look up not-null for %s.%s: %w", table, column, err)
+	}
+	return notNull, true, nil
+}
@@ -0,0 +1,131 @@
+package pginfer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFromClause(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want []tableRef
+	}{
+		{
+			name: "no from clause",
+			sql:  "SELECT 1 as one",
+			want: nil,
+		},
+		{
+			name: "single table",
+			sql:  "SELECT first_name FROM author WHERE first_name = $1;",
+			want: []tableRef{{Name: "author"}},
+		},
+		{
+			name: "inner join",
+			sql:  "SELECT a1.first_name FROM author a1 JOIN author a2 USING (author_id) WHERE a1.first_name = $1;",
+			want: []tableRef{{Name: "author", Alias: "a1"}, {Name: "author", Alias: "a2"}},
+		},
+		{
+			name: "left join marks right side nullable",
+			sql:  "SELECT a1.first_name, a2.first_name FROM author a1 LEFT JOIN author a2 ON a1.author_id = a2.author_id;",
+			want: []tableRef{{Name: "author", Alias: "a1"}, {Name: "author", Alias: "a2", Nullable: true}},
+		},
+		{
+			name: "right join marks left side nullable",
+			sql:  "SELECT a1.first_name, a2.first_name FROM author a1 RIGHT JOIN author a2 ON a1.author_id = a2.author_id;",
+			want: []tableRef{{Name: "author", Alias: "a1", Nullable: true}, {Name: "author", Alias: "a2"}},
+		},
+		{
+			name: "full join marks both sides nullable",
+			sql:  "SELECT a1.first_name, a2.first_name FROM author a1 FULL JOIN author a2 ON a1.author_id = a2.author_id;",
+			want: []tableRef{{Name: "author", Alias: "a1", Nullable: true}, {Name: "author", Alias: "a2", Nullable: true}},
+		},
+		{
+			name: "subquery in from is recognized, not parsed as a table named \"(select\"",
+			sql:  "SELECT sub.first_name FROM (SELECT first_name FROM author) sub JOIN author a2 USING (author_id);",
+			want: []tableRef{{IsSubquery: true, Alias: "sub"}, {Name: "author", Alias: "a2"}},
+		},
+		{
+			name: "from inside a function call isn't mistaken for the clause's from",
+			sql:  "SELECT extract(epoch FROM ts) AS t FROM author;",
+			want: []tableRef{{Name: "author"}},
+		},
+		{
+			name: "comma-joined tables are separate refs, not one bogus table",
+			sql:  "SELECT a.first_name, b.title FROM author a, book b;",
+			want: []tableRef{{Name: "author", Alias: "a"}, {Name: "book", Alias: "b"}},
+		},
+		{
+			name: "update has no from clause, resolves target table from update itself",
+			sql:  "UPDATE author SET first_name = $1 WHERE author_id = $2 RETURNING author_id, first_name;",
+			want: []tableRef{{Name: "author"}},
+		},
+		{
+			name: "having directly after from with no preceding where/group by",
+			sql:  "SELECT first_name FROM author HAVING count(*) > 1;",
+			want: []tableRef{{Name: "author"}},
+		},
+		{
+			name: "for update locking clause directly after from",
+			sql:  "SELECT first_name FROM author FOR UPDATE;",
+			want: []tableRef{{Name: "author"}},
+		},
+		{
+			name: "window clause directly after from",
+			sql:  "SELECT first_name FROM author WINDOW w AS (PARTITION BY author_id);",
+			want: []tableRef{{Name: "author"}},
+		},
+		{
+			name: "derived table's own where/group by doesn't truncate the outer from list early",
+			sql:  "SELECT sub.first_name, a2.first_name FROM (SELECT first_name FROM author WHERE author_id = $1 GROUP BY first_name HAVING count(*) > 0) sub JOIN author a2 USING (author_id) WHERE a2.first_name = $2;",
+			want: []tableRef{{IsSubquery: true, Alias: "sub"}, {Name: "author", Alias: "a2"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseFromClause(tt.sql))
+		})
+	}
+}
+
+func TestParseSelectList(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want []selectItem
+	}{
+		{
+			name: "unqualified column",
+			sql:  "SELECT first_name FROM author WHERE first_name = $1;",
+			want: []selectItem{{Column: "first_name", IsColumn: true}},
+		},
+		{
+			name: "qualified columns",
+			sql:  "SELECT a1.first_name, a2.first_name FROM author a1 JOIN author a2 USING (author_id);",
+			want: []selectItem{
+				{Qualifier: "a1", Column: "first_name", IsColumn: true},
+				{Qualifier: "a2", Column: "first_name", IsColumn: true},
+			},
+		},
+		{
+			name: "case expression isn't a column ref",
+			sql:  "SELECT CASE WHEN first_name = $1 THEN first_name ELSE NULL END AS maybe_name FROM author;",
+			want: []selectItem{{Alias: "maybe_name", IsColumn: false}},
+		},
+		{
+			name: "returning list",
+			sql:  "DELETE FROM author WHERE author_id = $1 RETURNING author_id, first_name;",
+			want: []selectItem{
+				{Column: "author_id", IsColumn: true},
+				{Column: "first_name", IsColumn: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseSelectList(tt.sql))
+		})
+	}
+}
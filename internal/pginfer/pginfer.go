@@ -0,0 +1,123 @@
+// Package pginfer infers the Postgres types of a query's input parameters
+// and output columns.
+package pginfer
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jschaf/pggen/internal/ast"
+	"github.com/jschaf/pggen/internal/pg"
+)
+
+// Analyzer identifies which layer of Inferrer.InferTypes resolved a
+// TypedQuery's types.
+type Analyzer string
+
+const (
+	// AnalyzerStatic means the query was resolved entirely in Go, from the
+	// query text and a cached catalog snapshot, with no round trip to
+	// Postgres.
+	AnalyzerStatic Analyzer = "static"
+	// AnalyzerDB means the static analyzer couldn't confidently resolve the
+	// query, so InferTypes fell back to PREPARE/DESCRIBE against Postgres.
+	AnalyzerDB Analyzer = "db"
+)
+
+// TypedQuery is a SourceQuery with Postgres types resolved for every input
+// parameter and output column.
+type TypedQuery struct {
+	Name        string
+	ResultKind  ast.ResultKind
+	Doc         []string
+	PreparedSQL string
+	Inputs      []InputParam
+	Outputs     []OutputColumn
+	// Analyzer records which layer of InferTypes resolved Outputs. Useful
+	// for debugging why codegen is slow on a large query set: a high share
+	// of AnalyzerDB means most queries are falling back to Postgres.
+	Analyzer Analyzer
+}
+
+// InputParam is a single named parameter to a query, like $1.
+type InputParam struct {
+	PgName string
+	PgType pg.Type
+}
+
+// OutputColumn is a single column in a query's result set.
+type OutputColumn struct {
+	PgName   string
+	PgType   pg.Type
+	Nullable bool
+}
+
+// Inferrer resolves the Postgres types of a query's parameters and output
+// columns using conn.
+type Inferrer struct {
+	conn    *pgx.Conn
+	catalog *pg.Catalog // lazily fetched the first time the static analyzer runs
+}
+
+// NewInferrer returns an Inferrer that infers types using conn.
+func NewInferrer(conn *pgx.Conn) *Inferrer {
+	return &Inferrer{conn: conn}
+}
+
+// ensureCatalog returns the cached catalog snapshot, fetching it from conn
+// on the first call so a large query set pays for introspection once
+// instead of once per query.
+func (i *Inferrer) ensureCatalog(ctx context.Context) (*pg.Catalog, error) {
+	if i.catalog == nil {
+		catalog, err := pg.FetchCatalog(ctx, i.conn, nil)
+		if err != nil {
+			return nil, err
+		}
+		i.catalog = catalog
+	}
+	return i.catalog, nil
+}
+
+// InferTypes determines the Postgres type of every input parameter and
+// output column of query.
+//
+// InferTypes first tries a pure-Go static analyzer that resolves column
+// types and nullability directly from the query text and a snapshot of the
+// database catalog (internal/pg.Catalog), with no round trip to Postgres.
+// When the static analyzer can't confidently resolve every output column -
+// for example the query has a CTE, a window function, or calls a function
+// like json_build_object - InferTypes falls back to the existing
+// PREPARE/DESCRIBE approach, which asks Postgres directly. The static path
+// is an order of magnitude faster across a large query set, since it skips
+// a network round trip per query; the DB fallback keeps pggen correct for
+// queries the static analyzer doesn't yet handle.
+func (i *Inferrer) InferTypes(query *ast.SourceQuery) (TypedQuery, error) {
+	if tq, ok, err := i.inferStatic(query); err != nil {
+		return TypedQuery{}, err
+	} else if ok {
+		return tq, nil
+	}
+	return i.inferDB(query)
+}
+
+// newDoc converts a comment group into the doc lines surfaced on a
+// TypedQuery, stripping the comment-leader dashes and dropping the pggen
+// directive line (e.g. "-- name: FindByFirstName :many").
+func newDoc(doc *ast.CommentGroup) []string {
+	if doc == nil {
+		return nil
+	}
+	lines := make([]string, 0, len(doc.List))
+	for _, c := range doc.List {
+		text := strings.TrimSpace(strings.TrimLeft(c.Text, "-"))
+		if strings.HasPrefix(text, "name:") {
+			continue // pggen directive, not documentation.
+		}
+		lines = append(lines, text)
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return lines
+}
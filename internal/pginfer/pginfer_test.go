@@ -33,6 +33,7 @@ func TestInferrer_InferTypes(t *testing.T) {
 					{PgName: "one", PgType: pg.Int4, Nullable: false},
 					{PgName: "two", PgType: pg.Text, Nullable: false},
 				},
+				Analyzer: AnalyzerStatic,
 			},
 		},
 		{
@@ -54,6 +55,7 @@ func TestInferrer_InferTypes(t *testing.T) {
 				Outputs: []OutputColumn{
 					{PgName: "first_name", PgType: pg.Text, Nullable: false},
 				},
+				Analyzer: AnalyzerDB,
 			},
 		},
 		{
@@ -73,8 +75,123 @@ func TestInferrer_InferTypes(t *testing.T) {
 					{PgName: "FirstName", PgType: pg.Text},
 				},
 				Outputs: []OutputColumn{
-					{PgName: "first_name", PgType: pg.Text, Nullable: true},
+					// a1 and a2 are joined with a plain INNER JOIN, so
+					// first_name can't be NULL on either side.
+					{PgName: "first_name", PgType: pg.Text, Nullable: false},
+				},
+				Analyzer: AnalyzerDB,
+			},
+		},
+		{
+			&ast.SourceQuery{
+				Name: "FindByFirstNameLeftJoin",
+				PreparedSQL: "SELECT a1.first_name, a2.first_name FROM author a1 " +
+					"LEFT JOIN author a2 ON a1.author_id = a2.author_id " +
+					"WHERE a1.first_name = $1;",
+				ParamNames: []string{"FirstName"},
+				ResultKind: ast.ResultKindMany,
+			},
+			TypedQuery{
+				Name:       "FindByFirstNameLeftJoin",
+				ResultKind: ast.ResultKindMany,
+				PreparedSQL: "SELECT a1.first_name, a2.first_name FROM author a1 " +
+					"LEFT JOIN author a2 ON a1.author_id = a2.author_id " +
+					"WHERE a1.first_name = $1;",
+				Inputs: []InputParam{
+					{PgName: "FirstName", PgType: pg.Text},
+				},
+				Outputs: []OutputColumn{
+					{PgName: "first_name", PgType: pg.Text, Nullable: false}, // a1: left side of LEFT JOIN
+					{PgName: "first_name", PgType: pg.Text, Nullable: true},  // a2: right side of LEFT JOIN
+				},
+				Analyzer: AnalyzerDB,
+			},
+		},
+		{
+			&ast.SourceQuery{
+				Name: "FindByFirstNameRightJoin",
+				PreparedSQL: "SELECT a1.first_name, a2.first_name FROM author a1 " +
+					"RIGHT JOIN author a2 ON a1.author_id = a2.author_id " +
+					"WHERE a2.first_name = $1;",
+				ParamNames: []string{"FirstName"},
+				ResultKind: ast.ResultKindMany,
+			},
+			TypedQuery{
+				Name:       "FindByFirstNameRightJoin",
+				ResultKind: ast.ResultKindMany,
+				PreparedSQL: "SELECT a1.first_name, a2.first_name FROM author a1 " +
+					"RIGHT JOIN author a2 ON a1.author_id = a2.author_id " +
+					"WHERE a2.first_name = $1;",
+				Inputs: []InputParam{
+					{PgName: "FirstName", PgType: pg.Text},
+				},
+				Outputs: []OutputColumn{
+					{PgName: "first_name", PgType: pg.Text, Nullable: true},  // a1: left side of RIGHT JOIN
+					{PgName: "first_name", PgType: pg.Text, Nullable: false}, // a2: right side of RIGHT JOIN
+				},
+				Analyzer: AnalyzerDB,
+			},
+		},
+		{
+			&ast.SourceQuery{
+				Name: "FindByFirstNameFullJoin",
+				PreparedSQL: "SELECT a1.first_name, a2.first_name FROM author a1 " +
+					"FULL JOIN author a2 ON a1.author_id = a2.author_id;",
+				ResultKind: ast.ResultKindMany,
+			},
+			TypedQuery{
+				Name:       "FindByFirstNameFullJoin",
+				ResultKind: ast.ResultKindMany,
+				PreparedSQL: "SELECT a1.first_name, a2.first_name FROM author a1 " +
+					"FULL JOIN author a2 ON a1.author_id = a2.author_id;",
+				Outputs: []OutputColumn{
+					{PgName: "first_name", PgType: pg.Text, Nullable: true}, // a1: FULL JOIN makes both sides nullable
+					{PgName: "first_name", PgType: pg.Text, Nullable: true}, // a2: FULL JOIN makes both sides nullable
+				},
+				// No parameters and every output is a plain column reference
+				// to a cataloged table, so this resolves statically instead
+				// of falling back to PREPARE/DESCRIBE.
+				Analyzer: AnalyzerStatic,
+			},
+		},
+		{
+			&ast.SourceQuery{
+				Name: "FindByFirstNameCase",
+				PreparedSQL: "SELECT CASE WHEN first_name = $1 THEN first_name ELSE NULL END AS maybe_name " +
+					"FROM author;",
+				ParamNames: []string{"FirstName"},
+				ResultKind: ast.ResultKindMany,
+			},
+			TypedQuery{
+				Name:       "FindByFirstNameCase",
+				ResultKind: ast.ResultKindMany,
+				PreparedSQL: "SELECT CASE WHEN first_name = $1 THEN first_name ELSE NULL END AS maybe_name " +
+					"FROM author;",
+				Inputs: []InputParam{
+					{PgName: "FirstName", PgType: pg.Text},
+				},
+				Outputs: []OutputColumn{
+					// A CASE expression can always evaluate to NULL, even
+					// though first_name itself is NOT NULL.
+					{PgName: "maybe_name", PgType: pg.Text, Nullable: true},
+				},
+				Analyzer: AnalyzerDB,
+			},
+		},
+		{
+			&ast.SourceQuery{
+				Name:        "FindByFirstNameCoalesce",
+				PreparedSQL: "SELECT COALESCE(first_name, 'unknown') AS name FROM author;",
+				ResultKind:  ast.ResultKindMany,
+			},
+			TypedQuery{
+				Name:        "FindByFirstNameCoalesce",
+				ResultKind:  ast.ResultKindMany,
+				PreparedSQL: "SELECT COALESCE(first_name, 'unknown') AS name FROM author;",
+				Outputs: []OutputColumn{
+					{PgName: "name", PgType: pg.Text, Nullable: true},
 				},
+				Analyzer: AnalyzerDB,
 			},
 		},
 		{
@@ -93,7 +210,8 @@ func TestInferrer_InferTypes(t *testing.T) {
 				Inputs: []InputParam{
 					{PgName: "AuthorID", PgType: pg.Int4},
 				},
-				Outputs: nil,
+				Outputs:  nil,
+				Analyzer: AnalyzerDB,
 			},
 		},
 		{
@@ -114,6 +232,32 @@ func TestInferrer_InferTypes(t *testing.T) {
 					{PgName: "author_id", PgType: pg.Int4, Nullable: false},
 					{PgName: "first_name", PgType: pg.Text, Nullable: false},
 				},
+				Analyzer: AnalyzerDB,
+			},
+		},
+		{
+			&ast.SourceQuery{
+				Name:        "UpdateAuthorFirstNameReturning",
+				PreparedSQL: "UPDATE author SET first_name = $1 WHERE author_id = $2 RETURNING author_id, first_name;",
+				ParamNames:  []string{"FirstName", "AuthorID"},
+				ResultKind:  ast.ResultKindMany,
+			},
+			TypedQuery{
+				Name:        "UpdateAuthorFirstNameReturning",
+				ResultKind:  ast.ResultKindMany,
+				PreparedSQL: "UPDATE author SET first_name = $1 WHERE author_id = $2 RETURNING author_id, first_name;",
+				Inputs: []InputParam{
+					{PgName: "FirstName", PgType: pg.Text},
+					{PgName: "AuthorID", PgType: pg.Int4},
+				},
+				Outputs: []OutputColumn{
+					// UPDATE has no FROM clause; author_id and first_name are
+					// both declared NOT NULL, and must resolve as such even
+					// though there's no FROM for resolveNullability to parse.
+					{PgName: "author_id", PgType: pg.Int4, Nullable: false},
+					{PgName: "first_name", PgType: pg.Text, Nullable: false},
+				},
+				Analyzer: AnalyzerDB,
 			},
 		},
 	}
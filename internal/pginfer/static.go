@@ -0,0 +1,192 @@
+package pginfer
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/jschaf/pggen/internal/ast"
+	"github.com/jschaf/pggen/internal/pg"
+)
+
+// literalIntRe and literalStringRe recognize the two literal forms the
+// static analyzer knows how to type: a bare integer and a single-quoted
+// string. Anything else - a function call, a cast, a parameter - isn't
+// resolved statically.
+var (
+	literalIntRe    = regexp.MustCompile(`^-?[0-9]+$`)
+	literalStringRe = regexp.MustCompile(`^'(?:[^']|'')*'$`)
+)
+
+// inferStatic tries to resolve query's output columns without a round trip
+// to Postgres. It handles two shapes:
+//   - a SELECT with no FROM clause, projecting only literal values, e.g.
+//     "SELECT 1 AS id, 'foo' AS name"
+//   - a SELECT or RETURNING list whose every output is a plain column
+//     reference to a table in the cached catalog snapshot (see
+//     ensureCatalog), combined with join-clause nullability the same way
+//     the DB analyzer's resolveNullability does
+//
+// A query with parameters, a CTE, a window function, a function call like
+// json_build_object, a subquery in FROM, or any output column the catalog
+// can't resolve returns ok=false so InferTypes falls back to the DB
+// analyzer, which can always resolve it by asking Postgres directly.
+func (i *Inferrer) inferStatic(query *ast.SourceQuery) (TypedQuery, bool, error) {
+	if len(query.ParamNames) > 0 {
+		return TypedQuery{}, false, nil
+	}
+	if query.ResultKind != ast.ResultKindOne && query.ResultKind != ast.ResultKindMany {
+		return TypedQuery{}, false, nil
+	}
+	sql := strings.TrimSpace(query.PreparedSQL)
+	sql = strings.TrimSuffix(sql, ";")
+
+	var outputs []OutputColumn
+	var ok bool
+	if findTopLevelKeyword(sql, "FROM") != -1 {
+		outputs, ok = i.resolveCatalogOutputs(sql)
+	} else {
+		upper := strings.ToUpper(sql)
+		if !strings.HasPrefix(upper, "SELECT ") {
+			return TypedQuery{}, false, nil
+		}
+		outputs, ok = parseLiteralProjection(sql[len("SELECT "):])
+	}
+	if !ok {
+		return TypedQuery{}, false, nil
+	}
+
+	return TypedQuery{
+		Name:        query.Name,
+		ResultKind:  query.ResultKind,
+		Doc:         newDoc(query.Doc),
+		PreparedSQL: query.PreparedSQL,
+		Outputs:     outputs,
+		Analyzer:    AnalyzerStatic,
+	}, true, nil
+}
+
+// resolveCatalogOutputs resolves sql's output columns entirely from the
+// cached catalog snapshot, reusing the same FROM/SELECT-list parsing
+// nullability.go uses for the DB analyzer. It returns ok=false the moment
+// any output can't be resolved this way, rather than returning a partial
+// result: a mix of catalog-resolved and unresolved columns is more
+// confusing than just falling back to the DB analyzer for the whole query.
+func (i *Inferrer) resolveCatalogOutputs(sql string) ([]OutputColumn, bool) {
+	refs := parseFromClause(sql)
+	if len(refs) == 0 {
+		return nil, false
+	}
+	items := parseSelectList(sql)
+	if len(items) == 0 {
+		return nil, false
+	}
+	catalog, err := i.ensureCatalog(context.Background())
+	if err != nil {
+		return nil, false // can't introspect; let the DB analyzer surface the real error.
+	}
+
+	outputs := make([]OutputColumn, len(items))
+	for idx, item := range items {
+		if !item.IsColumn {
+			return nil, false
+		}
+		ref := findTableRef(refs, item.Qualifier)
+		if ref == nil || ref.IsSubquery {
+			return nil, false
+		}
+		col, ok := findCatalogColumn(catalog, ref.Name, item.Column)
+		if !ok {
+			return nil, false
+		}
+		name := item.Column
+		if item.Alias != "" {
+			name = item.Alias
+		}
+		outputs[idx] = OutputColumn{
+			PgName:   name,
+			PgType:   col.Type,
+			Nullable: ref.Nullable || col.Nullable,
+		}
+	}
+	return outputs, true
+}
+
+// findCatalogColumn looks up table.column in catalog, matching on table
+// name only (not schema), the same way columnNotNull does for the DB
+// analyzer.
+func findCatalogColumn(catalog *pg.Catalog, table, column string) (pg.CatalogColumn, bool) {
+	for _, t := range catalog.Tables {
+		if t.Name != table {
+			continue
+		}
+		for _, c := range t.Columns {
+			if c.Name == column {
+				return c, true
+			}
+		}
+	}
+	return pg.CatalogColumn{}, false
+}
+
+// parseLiteralProjection parses a comma-separated list of "<literal> AS
+// <alias>" expressions. It returns ok=false if any item isn't a bare
+// integer or single-quoted string literal, or is missing an alias.
+func parseLiteralProjection(projection string) ([]OutputColumn, bool) {
+	items := splitTopLevel(projection, ',')
+	outputs := make([]OutputColumn, 0, len(items))
+	for _, item := range items {
+		expr, alias, ok := splitAlias(item)
+		if !ok {
+			return nil, false
+		}
+		var pgType pg.Type
+		switch {
+		case literalIntRe.MatchString(expr):
+			pgType = pg.Int4
+		case literalStringRe.MatchString(expr):
+			pgType = pg.Text
+		default:
+			return nil, false
+		}
+		outputs = append(outputs, OutputColumn{PgName: alias, PgType: pgType, Nullable: false})
+	}
+	return outputs, true
+}
+
+// splitAlias splits "<expr> AS <alias>" (case-insensitive "AS") into its
+// two parts.
+func splitAlias(item string) (expr, alias string, ok bool) {
+	item = strings.TrimSpace(item)
+	idx := strings.LastIndex(strings.ToUpper(item), " AS ")
+	if idx == -1 {
+		return "", "", false
+	}
+	expr = strings.TrimSpace(item[:idx])
+	alias = strings.TrimSpace(item[idx+len(" AS "):])
+	if expr == "" || alias == "" {
+		return "", "", false
+	}
+	return expr, alias, true
+}
+
+// splitTopLevel splits s on sep, ignoring occurrences of sep inside single
+// quotes so string literals containing commas aren't split incorrectly.
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	start := 0
+	inString := false
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\'':
+			inString = !inString
+		case sep:
+			if !inString {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	parts = append(parts, s[start:])
+	return parts
+}
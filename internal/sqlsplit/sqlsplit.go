@@ -0,0 +1,239 @@
+// Package sqlsplit splits a Postgres schema file containing multiple SQL
+// statements into individually runnable statements, similar to
+// golang-migrate's x-multi-statement mode. Splitting client-side lets pggen
+// run each statement one at a time against Postgres and report
+// exactly which statement failed, instead of handing Postgres one giant
+// multi-statement string and getting back an error with no location info.
+package sqlsplit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// DefaultMaxStatementSize bounds how large a single statement is allowed to
+// be, guarding against runaway parsing of malformed input (for example a
+// dollar-quote that's never closed). Matches golang-migrate's default.
+const DefaultMaxStatementSize = 10 * 1024 * 1024 // 10MiB
+
+// Statement is a single SQL statement extracted from a schema file, along
+// with the 1-based line on which it starts so errors can point a user at
+// the exact source location.
+type Statement struct {
+	Text string
+	Line int
+}
+
+// Error reports a failure to split or run a specific statement, identifying
+// the offending file, line, and statement text.
+type Error struct {
+	File      string
+	Line      int
+	Statement string
+	Err       error
+}
+
+func (e *Error) Error() string {
+	stmt := e.Statement
+	if len(stmt) > 200 {
+		stmt = stmt[:200] + "..."
+	}
+	return fmt.Sprintf("%s:%d: %s\n    statement: %s", e.File, e.Line, e.Err, stmt)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Split breaks src into individual statements, tracking the line each
+// statement starts on. It understands:
+//   - '...' and "..." quoted strings, where the quote character is escaped
+//     by doubling it
+//   - $tag$...$tag$ dollar-quoted strings, used for function bodies in
+//     CREATE FUNCTION/DO blocks
+//   - COPY ... FROM stdin; sections, which are terminated by a line
+//     containing only "\."
+//
+// A bare ';' terminates a statement only when it's not inside one of the
+// above. Statements are returned in file order; an unterminated quote or
+// COPY block is reported as an error rather than silently dropped.
+func Split(file, src string, maxStatementSize int) ([]Statement, error) {
+	if maxStatementSize <= 0 {
+		maxStatementSize = DefaultMaxStatementSize
+	}
+
+	var stmts []Statement
+	var b strings.Builder
+	line := 1
+	stmtLine := 1
+	inCopy := false
+
+	flush := func() {
+		text := strings.TrimSpace(b.String())
+		if text != "" {
+			stmts = append(stmts, Statement{Text: text, Line: stmtLine})
+		}
+		b.Reset()
+	}
+
+	i := 0
+	for i < len(src) {
+		if strings.TrimSpace(b.String()) == "" {
+			stmtLine = line
+		}
+		if b.Len() > maxStatementSize {
+			return nil, &Error{File: file, Line: stmtLine, Statement: b.String(),
+				Err: fmt.Errorf("statement exceeds max size of %d bytes", maxStatementSize)}
+		}
+
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			b.WriteByte(c)
+			i++
+			if inCopy && strings.HasPrefix(src[i:], "\\.") {
+				// Consume the COPY terminator line so it stays part of the
+				// statement, then flush: unlike every other statement kind,
+				// COPY ... FROM stdin is terminated by this marker line, not
+				// by a trailing ';'.
+				b.WriteString("\\.")
+				i += 2
+				inCopy = false
+				flush()
+			}
+
+		case inCopy:
+			b.WriteByte(c)
+			i++
+
+		case c == '\'' || c == '"':
+			quoted, n, err := readQuoted(src[i:], c)
+			if err != nil {
+				return nil, &Error{File: file, Line: stmtLine, Statement: b.String() + quoted, Err: err}
+			}
+			line += strings.Count(quoted, "\n")
+			b.WriteString(quoted)
+			i += n
+
+		case c == '$':
+			if tag, n, ok := readDollarTag(src[i:]); ok {
+				quoted, cn, err := readDollarQuoted(src[i:], tag)
+				if err != nil {
+					return nil, &Error{File: file, Line: stmtLine, Statement: b.String() + quoted, Err: err}
+				}
+				_ = n
+				line += strings.Count(quoted, "\n")
+				b.WriteString(quoted)
+				i += cn
+			} else {
+				b.WriteByte(c)
+				i++
+			}
+
+		case c == ';':
+			b.WriteByte(c)
+			i++
+			// A "COPY ... FROM stdin;" header ends in ';' just like any other
+			// statement, so it reaches this case before the default branch
+			// ever sees the completed header. Detect it here instead of
+			// flushing, since the data that follows isn't SQL at all.
+			if isCopyFromStdin(b.String()) {
+				inCopy = true
+			} else {
+				flush()
+			}
+
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	if inCopy {
+		return nil, &Error{File: file, Line: stmtLine, Statement: b.String(),
+			Err: fmt.Errorf("unterminated COPY ... FROM stdin block; missing \\. terminator line")}
+	}
+	flush()
+	return stmts, nil
+}
+
+// Run splits src into statements and executes them against conn one at a
+// time, stopping at and reporting the first one that fails. This gives
+// Postgres execution errors the same file:line reporting that Split
+// already gives parse errors, instead of one opaque error for whatever
+// happened to be running when a giant multi-statement string blew up.
+func Run(ctx context.Context, conn *pgx.Conn, file, src string, maxStatementSize int) error {
+	stmts, err := Split(file, src, maxStatementSize)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range stmts {
+		if _, err := conn.Exec(ctx, stmt.Text); err != nil {
+			return &Error{File: file, Line: stmt.Line, Statement: stmt.Text, Err: err}
+		}
+	}
+	return nil
+}
+
+// isCopyFromStdin reports whether the statement built up so far looks like
+// the start of a "COPY ... FROM stdin" command, meaning subsequent lines are
+// literal data, not SQL, until a lone "\." terminator line.
+func isCopyFromStdin(partial string) bool {
+	upper := strings.ToUpper(strings.TrimSpace(partial))
+	upper = strings.TrimSuffix(upper, ";")
+	return strings.HasPrefix(upper, "COPY ") && strings.HasSuffix(upper, "FROM STDIN")
+}
+
+// readQuoted reads a '...' or "..." string starting at s[0], which must be
+// the opening quote character q. Returns the quoted text (including both
+// delimiters) and the number of bytes consumed.
+func readQuoted(s string, q byte) (string, int, error) {
+	for i := 1; i < len(s); i++ {
+		if s[i] != q {
+			continue
+		}
+		// A doubled quote character is an escaped literal quote, not the end
+		// of the string.
+		if i+1 < len(s) && s[i+1] == q {
+			i++
+			continue
+		}
+		return s[:i+1], i + 1, nil
+	}
+	return s, len(s), fmt.Errorf("unterminated %c-quoted string", q)
+}
+
+// readDollarTag reads a $tag$ delimiter starting at s[0], which must be '$'.
+// Returns the tag including both dollar signs and whether a valid tag was
+// found (as opposed to a bare '$' used in an operator or placeholder).
+func readDollarTag(s string) (string, int, bool) {
+	for i := 1; i < len(s); i++ {
+		if s[i] == '$' {
+			return s[:i+1], i + 1, true
+		}
+		if !isTagByte(s[i]) {
+			return "", 0, false
+		}
+	}
+	return "", 0, false
+}
+
+func isTagByte(c byte) bool {
+	return c == '_' ||
+		(c >= 'a' && c <= 'z') ||
+		(c >= 'A' && c <= 'Z') ||
+		(c >= '0' && c <= '9')
+}
+
+// readDollarQuoted reads a dollar-quoted string body starting at s[0] (the
+// opening tag) through the matching closing tag. Returns the full quoted
+// text, including both tags, and the number of bytes consumed.
+func readDollarQuoted(s, tag string) (string, int, error) {
+	end := strings.Index(s[len(tag):], tag)
+	if end == -1 {
+		return s, len(s), fmt.Errorf("unterminated dollar-quoted string %s", tag)
+	}
+	n := len(tag) + end + len(tag)
+	return s[:n], n, nil
+}
@@ -0,0 +1,104 @@
+package sqlsplit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			name: "simple statements",
+			src:  "CREATE TABLE foo (id int);\nCREATE TABLE bar (id int);\n",
+			want: []string{
+				"CREATE TABLE foo (id int);",
+				"CREATE TABLE bar (id int);",
+			},
+		},
+		{
+			name: "semicolon inside quoted string",
+			src:  "INSERT INTO foo (name) VALUES ('a;b');\n",
+			want: []string{
+				"INSERT INTO foo (name) VALUES ('a;b');",
+			},
+		},
+		{
+			name: "escaped quote inside string",
+			src:  "INSERT INTO foo (name) VALUES ('it''s; fine');\n",
+			want: []string{
+				"INSERT INTO foo (name) VALUES ('it''s; fine');",
+			},
+		},
+		{
+			name: "dollar quoted function body",
+			src: "CREATE FUNCTION f() RETURNS int AS $$\n" +
+				"BEGIN\n" +
+				"  RETURN 1;\n" +
+				"END;\n" +
+				"$$ LANGUAGE plpgsql;\n",
+			want: []string{
+				"CREATE FUNCTION f() RETURNS int AS $$\nBEGIN\n  RETURN 1;\nEND;\n$$ LANGUAGE plpgsql;",
+			},
+		},
+		{
+			name: "tagged dollar quote",
+			src:  "DO $body$ BEGIN RAISE NOTICE 'hi; there'; END; $body$;\n",
+			want: []string{
+				"DO $body$ BEGIN RAISE NOTICE 'hi; there'; END; $body$;",
+			},
+		},
+		{
+			name: "copy from stdin",
+			src: "COPY author (author_id, first_name) FROM stdin;\n" +
+				"1\tJane\n" +
+				"2\tBob\n" +
+				"\\.\n" +
+				"CREATE TABLE after_copy (id int);\n",
+			want: []string{
+				"COPY author (author_id, first_name) FROM stdin;\n1\tJane\n2\tBob\n\\.",
+				"CREATE TABLE after_copy (id int);",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Split("schema.sql", tt.src, 0)
+			assert.NoError(t, err)
+			texts := make([]string, len(got))
+			for i, s := range got {
+				texts[i] = s.Text
+			}
+			assert.Equal(t, tt.want, texts)
+		})
+	}
+}
+
+func TestSplit_UnterminatedString(t *testing.T) {
+	_, err := Split("schema.sql", "SELECT 'unterminated;\n", 0)
+	assert.Error(t, err)
+}
+
+func TestSplit_StatementLineNumbers(t *testing.T) {
+	src := "CREATE TABLE foo (id int);\n\nCREATE TABLE bar (id int);\n"
+	got, err := Split("schema.sql", src, 0)
+	assert.NoError(t, err)
+	assert.Len(t, got, 2)
+	assert.Equal(t, 1, got[0].Line)
+	assert.Equal(t, 3, got[1].Line)
+}
+
+func TestError_Error(t *testing.T) {
+	err := &Error{File: "schema.sql", Line: 12, Statement: "CREATE TABLE broken (", Err: assertErr("syntax error")}
+	assert.Contains(t, err.Error(), "schema.sql:12:")
+	assert.Contains(t, err.Error(), "syntax error")
+}
+
+type assertErr string
+
+func (e assertErr) Error() string { return string(e) }
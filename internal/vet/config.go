@@ -0,0 +1,88 @@
+package vet
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bmatcuk/doublestar"
+)
+
+// RuleConfigEntry narrows or widens the rule set for query files matching
+// Glob, the same way --rule/--disable-rule narrow it for a whole run. If
+// Rules is non-empty, it's the only rule set that runs for a matching file,
+// same as --rule; otherwise DisableRules is subtracted from the run's
+// default rule set, same as --disable-rule.
+type RuleConfigEntry struct {
+	Glob         string   `json:"glob"`
+	Rules        []string `json:"rules,omitempty"`
+	DisableRules []string `json:"disableRules,omitempty"`
+}
+
+// RuleConfig is a --rule-config file: a list of glob-scoped rule overrides,
+// checked in order, first match wins. A query file that no entry's Glob
+// matches runs the rule set selected by --rule/--disable-rule.
+type RuleConfig []RuleConfigEntry
+
+// LoadRuleConfig reads and parses a --rule-config file.
+func LoadRuleConfig(path string) (RuleConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read rule config %s: %w", path, err)
+	}
+	var cfg RuleConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse rule config %s: %w", path, err)
+	}
+	for _, entry := range cfg {
+		if entry.Glob == "" {
+			return nil, fmt.Errorf("parse rule config %s: entry missing \"glob\"", path)
+		}
+	}
+	return cfg, nil
+}
+
+// RulesFor returns the rule set that applies to queryFile: the first entry
+// in c whose Glob matches queryFile, resolved with SelectRules; or
+// fallback - the rule set selected by --rule/--disable-rule for the whole
+// run - if no entry matches.
+//
+// queryFile is typically an absolute path - cmd/pggen's newVetCmd resolves
+// --query-glob matches to absolute paths before calling RulesFor - while a
+// rule-config Glob is written relative to the working directory a user runs
+// pggen from, e.g. "author/**/*.sql". doublestar.Match compares its pattern
+// and string literally, so matching queryFile as given against a relative
+// Glob would silently never succeed. To avoid that, RulesFor also matches
+// Glob against queryFile made relative to the current working directory,
+// falling back to the unmodified queryFile if that relative path can't be
+// computed (e.g. they're on different volumes) or if Glob is itself
+// absolute.
+func (c RuleConfig) RulesFor(queryFile string, fallback []Rule) ([]Rule, error) {
+	candidate := queryFile
+	if !filepath.IsAbs(queryFile) {
+		// already relative; nothing to do.
+	} else if cwd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(cwd, queryFile); err == nil {
+			candidate = rel
+		}
+	}
+	for _, entry := range c {
+		matched, err := doublestar.Match(entry.Glob, candidate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob %q in rule config: %w", entry.Glob, err)
+		}
+		if !matched && candidate != queryFile {
+			// Glob may itself be an absolute pattern; also try the original path.
+			matched, err = doublestar.Match(entry.Glob, queryFile)
+			if err != nil {
+				return nil, fmt.Errorf("invalid glob %q in rule config: %w", entry.Glob, err)
+			}
+		}
+		if !matched {
+			continue
+		}
+		return SelectRules(entry.Rules, entry.DisableRules)
+	}
+	return fallback, nil
+}
@@ -0,0 +1,83 @@
+package vet
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadRuleConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rules.json")
+	contents := `[
+		{"glob": "author/**/*.sql", "rules": ["no-select-star"]},
+		{"glob": "book/**/*.sql", "disableRules": ["max-cost"]}
+	]`
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadRuleConfig(path)
+	assert.NoError(t, err)
+	assert.Equal(t, RuleConfig{
+		{Glob: "author/**/*.sql", Rules: []string{"no-select-star"}},
+		{Glob: "book/**/*.sql", DisableRules: []string{"max-cost"}},
+	}, cfg)
+}
+
+func TestLoadRuleConfig_MissingGlob(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rules.json")
+	if err := os.WriteFile(path, []byte(`[{"rules": ["no-select-star"]}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := LoadRuleConfig(path)
+	assert.Error(t, err)
+}
+
+func TestRuleConfig_RulesFor(t *testing.T) {
+	fallback := []Rule{NoSelectStarRule{}, MaxCostRule{}}
+	cfg := RuleConfig{
+		{Glob: "author/**/*.sql", Rules: []string{"no-select-star"}},
+		{Glob: "book/**/*.sql", DisableRules: []string{"max-cost"}},
+	}
+
+	rules, err := cfg.RulesFor("author/queries.sql", fallback)
+	assert.NoError(t, err)
+	assert.Equal(t, []Rule{NoSelectStarRule{}}, rules)
+
+	rules, err = cfg.RulesFor("book/queries.sql", fallback)
+	assert.NoError(t, err)
+	for _, r := range rules {
+		assert.NotEqual(t, "max-cost", r.Name())
+	}
+
+	rules, err = cfg.RulesFor("other/queries.sql", fallback)
+	assert.NoError(t, err)
+	assert.Equal(t, fallback, rules)
+}
+
+// TestRuleConfig_RulesFor_AbsolutePath exercises RulesFor the way
+// newVetCmd actually calls it: with an absolute queryFile, since
+// expandSortGlobs always resolves --query-glob matches to absolute paths
+// before parseQueryFiles records them.
+func TestRuleConfig_RulesFor_AbsolutePath(t *testing.T) {
+	cwd, err := os.Getwd()
+	assert.NoError(t, err)
+
+	fallback := []Rule{NoSelectStarRule{}, MaxCostRule{}}
+	cfg := RuleConfig{
+		{Glob: "author/**/*.sql", Rules: []string{"no-select-star"}},
+	}
+
+	rules, err := cfg.RulesFor(filepath.Join(cwd, "author", "queries.sql"), fallback)
+	assert.NoError(t, err)
+	assert.Equal(t, []Rule{NoSelectStarRule{}}, rules)
+
+	rules, err = cfg.RulesFor(filepath.Join(cwd, "book", "queries.sql"), fallback)
+	assert.NoError(t, err)
+	assert.Equal(t, fallback, rules)
+}
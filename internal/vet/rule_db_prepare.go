@@ -0,0 +1,28 @@
+package vet
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jschaf/pggen/internal/ast"
+)
+
+// DBPrepareRule checks that a query successfully PREPAREs against Postgres,
+// catching syntax errors and references to tables/columns that don't
+// exist.
+type DBPrepareRule struct{}
+
+func (DBPrepareRule) Name() string { return "db-prepare" }
+
+func (DBPrepareRule) Check(ctx context.Context, conn *pgx.Conn, query *ast.SourceQuery) ([]Violation, error) {
+	if _, err := conn.Prepare(ctx, "", query.PreparedSQL); err != nil {
+		return []Violation{{
+			Rule:    "db-prepare",
+			Query:   query.Name,
+			Message: fmt.Sprintf("failed to PREPARE: %s", err),
+		}}, nil
+	}
+	_ = conn.Deallocate(ctx, "")
+	return nil, nil
+}
@@ -0,0 +1,107 @@
+package vet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jschaf/pggen/internal/ast"
+)
+
+// maxCostAnnotationRe matches a "-- pggen:max-cost 100" comment annotation
+// that declares the maximum planner cost a query is allowed to have.
+var maxCostAnnotationRe = regexp.MustCompile(`(?i)^\s*pggen:max-cost\s+(\d+(?:\.\d+)?)\s*$`)
+
+// MaxCostRule runs EXPLAIN (FORMAT JSON) on any query annotated with
+// "-- pggen:max-cost N" and fails if the planner's estimated total cost
+// exceeds N. This catches a missing index or an accidental cross join
+// before it ships, without requiring the table to be populated the way a
+// runtime benchmark would.
+type MaxCostRule struct{}
+
+func (MaxCostRule) Name() string { return "max-cost" }
+
+func (MaxCostRule) Check(ctx context.Context, conn *pgx.Conn, query *ast.SourceQuery) ([]Violation, error) {
+	maxCost, ok := parseMaxCostAnnotation(query.Doc)
+	if !ok {
+		return nil, nil
+	}
+
+	args := make([]interface{}, maxPlaceholder(query.PreparedSQL))
+	rows, err := conn.Query(ctx, "EXPLAIN (FORMAT JSON) "+query.PreparedSQL, args...)
+	if err != nil {
+		return nil, fmt.Errorf("explain query %s: %w", query.Name, err)
+	}
+	defer rows.Close()
+
+	var plans []struct {
+		Plan struct {
+			TotalCost float64 `json:"Total Cost"`
+		} `json:"Plan"`
+	}
+	for rows.Next() {
+		var raw string
+		if err := rows.Scan(&raw); err != nil {
+			return nil, fmt.Errorf("scan explain output for %s: %w", query.Name, err)
+		}
+		if err := json.Unmarshal([]byte(raw), &plans); err != nil {
+			return nil, fmt.Errorf("parse explain JSON for %s: %w", query.Name, err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(plans) == 0 {
+		return nil, fmt.Errorf("explain returned no plan for %s", query.Name)
+	}
+
+	cost := plans[0].Plan.TotalCost
+	if cost > maxCost {
+		return []Violation{{
+			Rule:    "max-cost",
+			Query:   query.Name,
+			Message: fmt.Sprintf("estimated total cost %.2f exceeds max-cost %.2f", cost, maxCost),
+		}}, nil
+	}
+	return nil, nil
+}
+
+// maxPlaceholder returns the highest $N placeholder in sql, so EXPLAIN can
+// be given that many NULL arguments and let Postgres infer each
+// placeholder's type from context, the same way it would for a real
+// PREPARE. Without this, any parameterized query - nearly all of them -
+// fails to bind and EXPLAIN never runs at all.
+func maxPlaceholder(sql string) int {
+	maxN := 0
+	for _, m := range placeholderRe.FindAllStringSubmatch(sql, -1) {
+		n, err := strconv.Atoi(m[1])
+		if err == nil && n > maxN {
+			maxN = n
+		}
+	}
+	return maxN
+}
+
+// parseMaxCostAnnotation looks for a "-- pggen:max-cost N" line in doc.
+func parseMaxCostAnnotation(doc *ast.CommentGroup) (float64, bool) {
+	if doc == nil {
+		return 0, false
+	}
+	for _, c := range doc.List {
+		text := strings.TrimSpace(strings.TrimLeft(c.Text, "-"))
+		m := maxCostAnnotationRe.FindStringSubmatch(text)
+		if m == nil {
+			continue
+		}
+		cost, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			continue
+		}
+		return cost, true
+	}
+	return 0, false
+}
@@ -0,0 +1,31 @@
+package vet
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jschaf/pggen/internal/ast"
+)
+
+// selectStarRe matches "SELECT *" and "SELECT table.*", but not
+// "COUNT(*)" or similar aggregate calls, since those aren't a projection
+// of every column.
+var selectStarRe = regexp.MustCompile(`(?i)\bSELECT\s+(?:[a-zA-Z_][a-zA-Z0-9_]*\.)?\*`)
+
+// NoSelectStarRule rejects "SELECT *", which silently breaks generated
+// code whenever a column is added, removed, or reordered.
+type NoSelectStarRule struct{}
+
+func (NoSelectStarRule) Name() string { return "no-select-star" }
+
+func (NoSelectStarRule) Check(_ context.Context, _ *pgx.Conn, query *ast.SourceQuery) ([]Violation, error) {
+	if selectStarRe.MatchString(query.PreparedSQL) {
+		return []Violation{{
+			Rule:    "no-select-star",
+			Query:   query.Name,
+			Message: "query uses SELECT *; name columns explicitly so added/removed columns don't silently change the generated struct",
+		}}, nil
+	}
+	return nil, nil
+}
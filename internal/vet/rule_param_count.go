@@ -0,0 +1,41 @@
+package vet
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jschaf/pggen/internal/ast"
+)
+
+// placeholderRe finds every "$N" parameter placeholder in a query.
+var placeholderRe = regexp.MustCompile(`\$(\d+)`)
+
+// ParamCountMatchesNameCountRule checks that a query's highest $N
+// placeholder matches the number of param names pggen parsed out of the
+// query's doc comment, catching a common copy-paste mistake where a
+// parameter is added or removed from the SQL but not from the name list.
+type ParamCountMatchesNameCountRule struct{}
+
+func (ParamCountMatchesNameCountRule) Name() string { return "param-count-matches-name-count" }
+
+func (ParamCountMatchesNameCountRule) Check(_ context.Context, _ *pgx.Conn, query *ast.SourceQuery) ([]Violation, error) {
+	maxN := 0
+	for _, m := range placeholderRe.FindAllStringSubmatch(query.PreparedSQL, -1) {
+		var n int
+		if _, err := fmt.Sscanf(m[1], "%d", &n); err == nil && n > maxN {
+			maxN = n
+		}
+	}
+	if maxN != len(query.ParamNames) {
+		return []Violation{{
+			Rule:  "param-count-matches-name-count",
+			Query: query.Name,
+			Message: fmt.Sprintf(
+				"query uses %d parameter placeholder(s) but has %d param name(s)",
+				maxN, len(query.ParamNames)),
+		}}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,37 @@
+package vet
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jschaf/pggen/internal/ast"
+)
+
+var (
+	updateDeleteRe = regexp.MustCompile(`(?i)^\s*(UPDATE|DELETE)\b`)
+	whereRe        = regexp.MustCompile(`(?i)\bWHERE\b`)
+)
+
+// RequireWhereOnUpdateDeleteRule flags UPDATE/DELETE queries with no WHERE
+// clause, which almost always means every row in the table was meant to be
+// excluded from the statement by accident.
+type RequireWhereOnUpdateDeleteRule struct{}
+
+func (RequireWhereOnUpdateDeleteRule) Name() string { return "require-where-on-update-delete" }
+
+func (RequireWhereOnUpdateDeleteRule) Check(_ context.Context, _ *pgx.Conn, query *ast.SourceQuery) ([]Violation, error) {
+	sql := strings.TrimSpace(query.PreparedSQL)
+	if !updateDeleteRe.MatchString(sql) {
+		return nil, nil
+	}
+	if whereRe.MatchString(sql) {
+		return nil, nil
+	}
+	return []Violation{{
+		Rule:    "require-where-on-update-delete",
+		Query:   query.Name,
+		Message: "UPDATE/DELETE with no WHERE clause affects every row in the table",
+	}}, nil
+}
@@ -0,0 +1,148 @@
+// Package vet implements pggen's query linter: a set of pluggable rules
+// that check each query for common mistakes without generating any code.
+package vet
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jschaf/pggen/internal/ast"
+)
+
+// Violation is a single rule failure for a single query.
+type Violation struct {
+	Rule    string
+	Query   string
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: [%s] %s", v.Query, v.Rule, v.Message)
+}
+
+// Rule checks a single query for a specific class of mistake. Rules that
+// don't need a database connection, like NoSelectStarRule, simply ignore conn.
+// A caller can implement Rule for a check specific to their own schema and
+// make it selectable by name, the same way a built-in rule is, by calling
+// Register.
+type Rule interface {
+	// Name is the rule's identifier, used on the --rule/--disable-rule flags
+	// and in a rule config file.
+	Name() string
+	// Check returns one Violation per problem found in query. An empty,
+	// nil-error result means the query passed the rule.
+	Check(ctx context.Context, conn *pgx.Conn, query *ast.SourceQuery) ([]Violation, error)
+}
+
+// defaultRules are the rules `pggen vet` runs unless narrowed by --rule or
+// --disable-rule.
+var defaultRules = []Rule{
+	DBPrepareRule{},
+	NoSelectStarRule{},
+	RequireWhereOnUpdateDeleteRule{},
+	ParamCountMatchesNameCountRule{},
+	MaxCostRule{},
+}
+
+// registered holds rules added with Register, keyed by Name(). A registered
+// rule doesn't join defaultRules - it has to be named explicitly with
+// --rule or a rule-config entry - the same way a database/sql driver has to
+// be named explicitly in a DSN after Register.
+var registered = map[string]Rule{}
+
+// DefaultRules returns the built-in rules `pggen vet` runs by default.
+func DefaultRules() []Rule {
+	rules := make([]Rule, len(defaultRules))
+	copy(rules, defaultRules)
+	return rules
+}
+
+// Register makes rule selectable by ByName (and so by --rule,
+// --disable-rule, and a rule-config entry) under rule.Name(), without
+// adding it to the default set `pggen vet` runs when no rule is named
+// explicitly. It panics if name is already registered, either by a
+// built-in rule or a previous Register call, so a name collision is caught
+// at startup rather than silently shadowing a rule.
+func Register(rule Rule) {
+	name := rule.Name()
+	if _, ok := ByName(name); ok {
+		panic(fmt.Sprintf("vet: Register called twice for rule %q", name))
+	}
+	registered[name] = rule
+}
+
+// ByName looks up a rule by Name(), first among the built-ins, then among
+// rules added with Register.
+func ByName(name string) (Rule, bool) {
+	for _, r := range defaultRules {
+		if r.Name() == name {
+			return r, true
+		}
+	}
+	r, ok := registered[name]
+	return r, ok
+}
+
+// SelectRules builds the rule set to check queries against: every rule
+// named in enable, resolved with ByName so a --rule flag can also select a
+// rule added with Register; or, if enable is empty, every default rule
+// except those named in disable. It's the shared selection logic behind
+// both --rule/--disable-rule and a rule-config entry's Rules/DisableRules.
+func SelectRules(enable, disable []string) ([]Rule, error) {
+	if len(enable) > 0 {
+		rules := make([]Rule, 0, len(enable))
+		for _, name := range enable {
+			rule, ok := ByName(name)
+			if !ok {
+				return nil, fmt.Errorf("unknown rule: %s", name)
+			}
+			rules = append(rules, rule)
+		}
+		return rules, nil
+	}
+	skip := make(map[string]bool, len(disable))
+	for _, name := range disable {
+		skip[name] = true
+	}
+	var rules []Rule
+	for _, rule := range defaultRules {
+		if !skip[rule.Name()] {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// Runner checks a set of queries against a set of rules.
+type Runner struct {
+	Rules []Rule
+}
+
+// NewRunner returns a Runner that checks queries against rules.
+func NewRunner(rules []Rule) *Runner {
+	return &Runner{Rules: rules}
+}
+
+// Check runs every rule against every query and returns all violations
+// found, continuing past individual rule errors so one bad rule doesn't
+// stop the whole run; rule errors are themselves reported as violations
+// under a synthetic "rule-error" rule name.
+func (r *Runner) Check(ctx context.Context, conn *pgx.Conn, queries []*ast.SourceQuery) []Violation {
+	var violations []Violation
+	for _, query := range queries {
+		for _, rule := range r.Rules {
+			vs, err := rule.Check(ctx, conn, query)
+			if err != nil {
+				violations = append(violations, Violation{
+					Rule:    "rule-error",
+					Query:   query.Name,
+					Message: fmt.Sprintf("rule %s failed to run: %s", rule.Name(), err),
+				})
+				continue
+			}
+			violations = append(violations, vs...)
+		}
+	}
+	return violations
+}
@@ -0,0 +1,131 @@
+package vet
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jschaf/pggen/internal/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoSelectStarRule(t *testing.T) {
+	tests := []struct {
+		name      string
+		sql       string
+		violation bool
+	}{
+		{"select star", "SELECT * FROM author;", true},
+		{"qualified select star", "SELECT a.* FROM author a;", true},
+		{"count star is fine", "SELECT COUNT(*) FROM author;", false},
+		{"explicit columns", "SELECT first_name FROM author;", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vs, err := NoSelectStarRule{}.Check(context.Background(), nil, &ast.SourceQuery{Name: "Q", PreparedSQL: tt.sql})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.violation, len(vs) > 0)
+		})
+	}
+}
+
+func TestRequireWhereOnUpdateDeleteRule(t *testing.T) {
+	tests := []struct {
+		name      string
+		sql       string
+		violation bool
+	}{
+		{"delete without where", "DELETE FROM author;", true},
+		{"delete with where", "DELETE FROM author WHERE author_id = $1;", false},
+		{"update without where", "UPDATE author SET first_name = $1;", true},
+		{"update with where", "UPDATE author SET first_name = $1 WHERE author_id = $2;", false},
+		{"select is unaffected", "SELECT * FROM author;", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vs, err := RequireWhereOnUpdateDeleteRule{}.Check(context.Background(), nil, &ast.SourceQuery{Name: "Q", PreparedSQL: tt.sql})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.violation, len(vs) > 0)
+		})
+	}
+}
+
+func TestParamCountMatchesNameCountRule(t *testing.T) {
+	tests := []struct {
+		name       string
+		sql        string
+		paramNames []string
+		violation  bool
+	}{
+		{"matching count", "SELECT * FROM author WHERE author_id = $1;", []string{"AuthorID"}, false},
+		{"missing name", "SELECT * FROM author WHERE author_id = $1;", nil, true},
+		{"extra name", "SELECT * FROM author;", []string{"AuthorID"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := &ast.SourceQuery{Name: "Q", PreparedSQL: tt.sql, ParamNames: tt.paramNames}
+			vs, err := ParamCountMatchesNameCountRule{}.Check(context.Background(), nil, query)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.violation, len(vs) > 0)
+		})
+	}
+}
+
+// fakeRule is a minimal Rule used to test Register/ByName/SelectRules
+// without depending on a built-in rule's behavior.
+type fakeRule struct{ name string }
+
+func (r fakeRule) Name() string { return r.name }
+
+func (r fakeRule) Check(context.Context, *pgx.Conn, *ast.SourceQuery) ([]Violation, error) {
+	return nil, nil
+}
+
+func TestRegister_ByName(t *testing.T) {
+	Register(fakeRule{name: "test-fake-rule"})
+
+	rule, ok := ByName("test-fake-rule")
+	assert.True(t, ok)
+	assert.Equal(t, "test-fake-rule", rule.Name())
+
+	_, ok = ByName("no-such-rule")
+	assert.False(t, ok)
+}
+
+func TestRegister_PanicsOnDuplicateName(t *testing.T) {
+	Register(fakeRule{name: "test-duplicate-rule"})
+	assert.Panics(t, func() { Register(fakeRule{name: "test-duplicate-rule"}) })
+}
+
+func TestSelectRules(t *testing.T) {
+	Register(fakeRule{name: "test-select-rules-custom"})
+
+	rules, err := SelectRules([]string{"no-select-star", "test-select-rules-custom"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []Rule{NoSelectStarRule{}, fakeRule{name: "test-select-rules-custom"}}, rules)
+
+	_, err = SelectRules([]string{"no-such-rule"}, nil)
+	assert.Error(t, err)
+
+	rules, err = SelectRules(nil, []string{"no-select-star"})
+	assert.NoError(t, err)
+	for _, r := range rules {
+		assert.NotEqual(t, "no-select-star", r.Name())
+	}
+}
+
+func TestParseMaxCostAnnotation(t *testing.T) {
+	doc := &ast.CommentGroup{List: []*ast.LineComment{
+		{Text: "-- FindAuthors returns every author."},
+		{Text: "-- pggen:max-cost 100"},
+	}}
+	cost, ok := parseMaxCostAnnotation(doc)
+	assert.True(t, ok)
+	assert.Equal(t, 100.0, cost)
+
+	_, ok = parseMaxCostAnnotation(&ast.CommentGroup{List: []*ast.LineComment{{Text: "-- no annotation here"}}})
+	assert.False(t, ok)
+
+	_, ok = parseMaxCostAnnotation(nil)
+	assert.False(t, ok)
+}